@@ -0,0 +1,319 @@
+// Copyright 2018 John Scherff
+//
+// Licensed under the Apache License, version 2.0 (the "License");
+// you may not use this file except in compliance with the License.
+// You may obtain a copy of the License at
+//
+//     http://www.apache.org/licenses/LICENSE-2.0
+//
+// Unless required by applicable law or agreed to in writing, software
+// distributed under the License is distributed on an "AS IS" BASIS,
+// WITHOUT WARRANTIES OR CONDITIONS OF ANY KIND, either express or implied.
+// See the License for the specific language governing permissions and
+// limitations under the License.
+
+// Package analysis checks the rules of DMN decision tables for overlaps,
+// where more than one rule matches the same inputs, and gaps, where no
+// rule matches.
+package analysis
+
+import (
+	`fmt`
+	`strings`
+	`github.com/jscherff/dmnsdk/feel`
+	`github.com/jscherff/dmnsdk/model`
+)
+
+// ------------------------------------------------------------------------
+// Region.
+// ------------------------------------------------------------------------
+
+// Region is a subset of the input space of a decision table: one Set of
+// values per input, in column order.
+type Region struct {
+	Inputs			[]string
+	Sets			[]Set
+}
+
+// String implements the Stringer interface for Region.
+func (this *Region) String() (string) {
+
+	cols := make([]string, len(this.Sets))
+
+	for i, set := range this.Sets {
+		cols[i] = fmt.Sprintf(`%s: %s`, this.Inputs[i], set)
+	}
+
+	return strings.Join(cols, `; `)
+}
+
+// ------------------------------------------------------------------------
+// Overlap.
+// ------------------------------------------------------------------------
+
+// Overlap reports two rules that match the same inputs. Region is the
+// witness: the inputs that both rules match. Conflict is set when the two
+// rules have different output entries.
+type Overlap struct {
+	RuleA			string
+	RuleB			string
+	Region			*Region
+	Conflict		bool
+}
+
+// ------------------------------------------------------------------------
+// Report.
+// ------------------------------------------------------------------------
+
+// Report contains the overlaps and gaps found in a decision table.
+type Report struct {
+	HitPolicy		string
+	Overlaps		[]*Overlap
+	Gaps			[]*Region
+}
+
+// Violations returns the overlaps that the hit policy of the table does
+// not permit: every overlap for UNIQUE, and conflicting overlaps for ANY.
+func (this *Report) Violations() (overlaps []*Overlap) {
+
+	for _, ov := range this.Overlaps {
+		switch this.HitPolicy {
+		case model.HitPolicyUnique:
+			overlaps = append(overlaps, ov)
+		case model.HitPolicyAny:
+			if ov.Conflict {
+				overlaps = append(overlaps, ov)
+			}
+		}
+	}
+
+	return overlaps
+}
+
+// ------------------------------------------------------------------------
+// Analysis.
+// ------------------------------------------------------------------------
+
+// Analyze parses the input entries of every rule of a decision table and
+// reports each pair of overlapping rules and the regions of the input
+// space that no rule covers. The value domain of each input is taken from
+// the typeRef of its input expression, or inferred from its entries if no
+// typeRef is set. Entries that reference variables or use comparisons
+// the domain does not support cannot be analyzed and produce an error.
+func Analyze(dt *model.DecisionTable) (*Report, error) {
+
+	exps := dt.InputExpressions()
+
+	rpt := &Report{HitPolicy: strings.ToUpper(strings.TrimSpace(dt.HitPolicy))}
+
+	if rpt.HitPolicy == `` {
+		rpt.HitPolicy = model.HitPolicyUnique
+	}
+
+	names := make([]string, len(exps))
+	kinds := make([]Kind, len(exps))
+	parsed := make([][]*feel.UnaryTests, len(exps))
+
+	for i, exp := range exps {
+		names[i] = strings.TrimSpace(exp.Text)
+	}
+
+	for _, rule := range dt.Rules {
+
+		if len(rule.InputEntries) != len(exps) {
+			return nil, fmt.Errorf(`rule %s has %d input entries, expected %d`,
+				rule.Id, len(rule.InputEntries), len(exps))
+		}
+
+		for i, entry := range rule.InputEntries {
+			if ut, err := feel.ParseUnaryTests(entry.Text); err != nil {
+				return nil, fmt.Errorf(`rule %s input entry %s: %v`, rule.Id, entry.Id, err)
+			} else {
+				parsed[i] = append(parsed[i], ut)
+			}
+		}
+	}
+
+	for i, exp := range exps {
+		if kind, ok := kindOf(exp.TypeRef); ok {
+			kinds[i] = kind
+		} else {
+			kinds[i] = inferKind(parsed[i])
+		}
+	}
+
+	sets := make([][]Set, len(dt.Rules))
+
+	for r, rule := range dt.Rules {
+
+		sets[r] = make([]Set, len(exps))
+
+		for i, entry := range rule.InputEntries {
+			if set, err := newSet(parsed[i][r], kinds[i]); err != nil {
+				return nil, fmt.Errorf(`rule %s input entry %s: %v`, rule.Id, entry.Id, err)
+			} else {
+				sets[r][i] = set
+			}
+		}
+	}
+
+	rpt.Overlaps = overlaps(dt.Rules, sets, names)
+
+	all := make([]Set, len(exps))
+
+	for i, kind := range kinds {
+		all[i] = universe(kind)
+	}
+
+	rules := make([]int, len(dt.Rules))
+
+	for r := range rules {
+		rules[r] = r
+	}
+
+	var gaps [][]Set
+
+	findGaps(sets, all, 0, nil, rules, &gaps)
+
+	for _, gap := range mergeRegions(gaps) {
+		rpt.Gaps = append(rpt.Gaps, &Region{names, gap})
+	}
+
+	return rpt, nil
+}
+
+// overlaps intersects every pair of rules column by column and returns
+// the pairs whose intersection is non-empty in every column.
+func overlaps(rules []*model.Rule, sets [][]Set, names []string) (ovs []*Overlap) {
+
+	for a := 0; a < len(rules); a++ {
+
+		next:
+		for b := a + 1; b < len(rules); b++ {
+
+			region := make([]Set, len(names))
+
+			for i := range names {
+				if region[i] = sets[a][i].Intersect(sets[b][i]); region[i].Empty() {
+					continue next
+				}
+			}
+
+			ovs = append(ovs, &Overlap{
+				RuleA: rules[a].Id,
+				RuleB: rules[b].Id,
+				Region: &Region{names, region},
+				Conflict: !sameOutputs(rules[a], rules[b]),
+			})
+		}
+	}
+
+	return ovs
+}
+
+// findGaps partitions the values of column col into cells that each rule
+// either covers completely or not at all, and recurses into the next
+// column with the rules covering each cell. A cell that no rule covers is
+// a gap; the remaining columns of a gap accept any value.
+func findGaps(sets [][]Set, all []Set, col int, prefix []Set, rules []int, gaps *[][]Set) {
+
+	if len(rules) == 0 {
+		gap := append(append([]Set{}, prefix...), all[col:]...)
+		*gaps = append(*gaps, gap)
+		return
+	}
+
+	if col == len(all) {
+		return
+	}
+
+	cells := []Set{all[col]}
+
+	for _, r := range rules {
+
+		var split []Set
+
+		for _, cell := range cells {
+			if in := cell.Intersect(sets[r][col]); !in.Empty() {
+				split = append(split, in)
+			}
+			if out := cell.Subtract(sets[r][col]); !out.Empty() {
+				split = append(split, out)
+			}
+		}
+
+		cells = split
+	}
+
+	for _, cell := range cells {
+
+		var covering []int
+
+		for _, r := range rules {
+			if !cell.Intersect(sets[r][col]).Empty() {
+				covering = append(covering, r)
+			}
+		}
+
+		findGaps(sets, all, col+1, append(prefix[:col:col], cell), covering, gaps)
+	}
+}
+
+// mergeRegions repeatedly combines regions that differ in only one
+// column, so that gaps are reported in as few regions as possible.
+func mergeRegions(regions [][]Set) ([][]Set) {
+
+	for merged := true; merged; {
+
+		merged = false
+
+		outer:
+		for a := 0; a < len(regions); a++ {
+			for b := a + 1; b < len(regions); b++ {
+
+				if col, ok := differsInOne(regions[a], regions[b]); ok {
+					regions[a][col] = regions[a][col].Union(regions[b][col])
+					regions = append(regions[:b], regions[b+1:]...)
+					merged = true
+					break outer
+				}
+			}
+		}
+	}
+
+	return regions
+}
+
+// differsInOne reports whether two regions are equal in all columns but
+// one, and returns that column.
+func differsInOne(a, b []Set) (int, bool) {
+
+	col := -1
+
+	for i := range a {
+		if a[i].String() != b[i].String() {
+			if col >= 0 {
+				return 0, false
+			}
+			col = i
+		}
+	}
+
+	return col, col >= 0
+}
+
+// sameOutputs reports whether two rules have the same output entries.
+func sameOutputs(a, b *model.Rule) (bool) {
+
+	if len(a.OutputEntries) != len(b.OutputEntries) {
+		return false
+	}
+
+	for i := range a.OutputEntries {
+		if strings.TrimSpace(a.OutputEntries[i].Text) != strings.TrimSpace(b.OutputEntries[i].Text) {
+			return false
+		}
+	}
+
+	return true
+}
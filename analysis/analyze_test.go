@@ -0,0 +1,138 @@
+package analysis
+
+import (
+	`strings`
+	`testing`
+	`github.com/jscherff/dmnsdk/model`
+)
+
+// testTable creates a decision table with inputs of the given typeRefs and
+// a single output. Each rule lists its input entries followed by its
+// output entry and is identified by its position, starting with r1.
+func testTable(hitPolicy string, typeRefs []string, rules ...[]string) (*model.DecisionTable) {
+
+	dt := &model.DecisionTable{
+		HitPolicy: hitPolicy,
+		Outputs: []*model.Output{{Name: `out`, TypeRef: `string`}},
+	}
+
+	for i, typeRef := range typeRefs {
+		dt.Inputs = append(dt.Inputs, &model.Input{
+			InputExpressions: []*model.InputExpression{{Text: string(rune('a' + i)), TypeRef: typeRef}},
+		})
+	}
+
+	for i, r := range rules {
+
+		rule := &model.Rule{Id: `r` + string(rune('1' + i))}
+
+		for _, text := range r[:len(r)-1] {
+			rule.InputEntries = append(rule.InputEntries, &model.InputEntry{Text: text})
+		}
+
+		rule.OutputEntries = []*model.OutputEntry{{Text: r[len(r)-1]}}
+		dt.Rules = append(dt.Rules, rule)
+	}
+
+	return dt
+}
+
+func TestAnalyze(t *testing.T) {
+
+	tests := []struct {
+		name		string
+		table		*model.DecisionTable
+		overlaps	[]string
+		violations	int
+		gaps		[]string
+	}{
+		{`complete integer partition`,
+			testTable(``, []string{`integer`},
+				[]string{`< 10`, `"a"`}, []string{`[10..20]`, `"b"`}, []string{`> 20`, `"c"`}),
+			nil, 0, nil},
+		{`integer overlap and gap`,
+			testTable(`UNIQUE`, []string{`integer`},
+				[]string{`< 10`, `"a"`}, []string{`[5..20]`, `"b"`}, []string{`> 30`, `"c"`}),
+			[]string{`r1 r2 conflict a: [5..9]`}, 1, []string{`a: [21..30]`}},
+		{`any permits agreeing overlap`,
+			testTable(`ANY`, []string{`integer`},
+				[]string{`<= 10`, `"a"`}, []string{`>= 5`, `"a"`}),
+			[]string{`r1 r2 a: [5..10]`}, 0, nil},
+		{`any rejects conflicting overlap`,
+			testTable(`ANY`, []string{`integer`},
+				[]string{`<= 10`, `"a"`}, []string{`>= 5`, `"b"`}),
+			[]string{`r1 r2 conflict a: [5..10]`}, 1, nil},
+		{`collect permits overlap`,
+			testTable(`COLLECT`, []string{`integer`},
+				[]string{`-`, `"a"`}, []string{`5`, `"b"`}),
+			[]string{`r1 r2 conflict a: 5`}, 0, nil},
+		{`boolean gap`,
+			testTable(``, []string{`boolean`},
+				[]string{`true`, `"a"`}),
+			nil, 0, []string{`a: false`}},
+		{`two inputs`,
+			testTable(``, []string{`string`, `boolean`},
+				[]string{`"x"`, `true`, `"a"`}, []string{`"x"`, `false`, `"b"`}, []string{`not("x")`, `-`, `"c"`}),
+			nil, 0, nil},
+		{`two inputs overlap`,
+			testTable(``, []string{`string`, `boolean`},
+				[]string{`"x", "y"`, `-`, `"a"`}, []string{`"y"`, `true`, `"b"`}, []string{`not("x", "y")`, `-`, `"c"`}),
+			[]string{`r1 r2 conflict a: "y"; b: true`}, 1, nil},
+	}
+
+	for _, tt := range tests {
+
+		rpt, err := Analyze(tt.table)
+
+		if err != nil {
+			t.Errorf(`%s: Analyze: %v`, tt.name, err)
+			continue
+		}
+
+		var overlaps []string
+
+		for _, ov := range rpt.Overlaps {
+			s := ov.RuleA + ` ` + ov.RuleB
+			if ov.Conflict {
+				s += ` conflict`
+			}
+			overlaps = append(overlaps, s + ` ` + ov.Region.String())
+		}
+
+		if strings.Join(overlaps, `, `) != strings.Join(tt.overlaps, `, `) {
+			t.Errorf(`%s: overlaps %v, want %v`, tt.name, overlaps, tt.overlaps)
+		}
+
+		if got := len(rpt.Violations()); got != tt.violations {
+			t.Errorf(`%s: %d violations, want %d`, tt.name, got, tt.violations)
+		}
+
+		if len(rpt.Gaps) != len(tt.gaps) {
+			t.Errorf(`%s: gaps %v, want %d`, tt.name, rpt.Gaps, len(tt.gaps))
+			continue
+		}
+
+		for i, gap := range rpt.Gaps {
+			if gap.String() != tt.gaps[i] {
+				t.Errorf(`%s: gap %s, want %s`, tt.name, gap, tt.gaps[i])
+			}
+		}
+	}
+}
+
+func TestAnalyzeErrors(t *testing.T) {
+
+	tests := []struct {
+		name	string
+		table	*model.DecisionTable
+	}{
+		{`variable endpoint`, testTable(``, []string{`integer`}, []string{`< limit`, `"a"`})},
+		{`syntax error`, testTable(``, []string{`integer`}, []string{`[1..`, `"a"`})},
+	}
+
+	for _, tt := range tests {
+		if _, err := Analyze(tt.table); err == nil {
+			t.Errorf(`%s: Analyze succeeded, want error`, tt.name)
+		}
+	}
+}
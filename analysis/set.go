@@ -0,0 +1,577 @@
+// Copyright 2018 John Scherff
+//
+// Licensed under the Apache License, version 2.0 (the "License");
+// you may not use this file except in compliance with the License.
+// You may obtain a copy of the License at
+//
+//     http://www.apache.org/licenses/LICENSE-2.0
+//
+// Unless required by applicable law or agreed to in writing, software
+// distributed under the License is distributed on an "AS IS" BASIS,
+// WITHOUT WARRANTIES OR CONDITIONS OF ANY KIND, either express or implied.
+// See the License for the specific language governing permissions and
+// limitations under the License.
+
+package analysis
+
+import (
+	`fmt`
+	`math`
+	`sort`
+	`strconv`
+	`strings`
+	`time`
+	`github.com/jscherff/dmnsdk/feel`
+)
+
+// ------------------------------------------------------------------------
+// Kind.
+// ------------------------------------------------------------------------
+
+// Kind is the value domain of a decision table input, derived from the
+// typeRef of its input expression.
+type Kind int
+
+const (
+	KindString Kind = iota
+	KindBoolean
+	KindInteger
+	KindNumber
+	KindDate
+)
+
+// kindOf maps a DMN typeRef to a Kind. An empty or unknown typeRef is
+// inferred from the literals used in the input entries.
+func kindOf(typeRef string) (Kind, bool) {
+
+	switch strings.ToLower(typeRef) {
+	case `string`:
+		return KindString, true
+	case `boolean`:
+		return KindBoolean, true
+	case `integer`, `long`:
+		return KindInteger, true
+	case `double`:
+		return KindNumber, true
+	case `date`:
+		return KindDate, true
+	default:
+		return KindString, false
+	}
+}
+
+// inferKind returns the Kind of the first literal endpoint in a column of
+// unary tests.
+func inferKind(uts []*feel.UnaryTests) (Kind) {
+
+	for _, ut := range uts {
+		for _, test := range ut.Tests {
+
+			var ep feel.Expr
+
+			switch test := test.(type) {
+			case *feel.Comparison:
+				ep = test.Endpoint
+			case *feel.Interval:
+				ep = test.Start
+			}
+
+			switch ep.(type) {
+			case *feel.NumberLit:
+				return KindNumber
+			case *feel.BoolLit:
+				return KindBoolean
+			case *feel.TemporalLit:
+				return KindDate
+			case *feel.StringLit:
+				return KindString
+			}
+		}
+	}
+
+	return KindString
+}
+
+// ------------------------------------------------------------------------
+// Set.
+// ------------------------------------------------------------------------
+
+// Set is a set of input values that a unary test accepts. Sets of the same
+// Kind can be combined; String renders the set as FEEL unary tests.
+type Set interface {
+	Union(Set) (Set)
+	Intersect(Set) (Set)
+	Subtract(Set) (Set)
+	Empty() (bool)
+	String() (string)
+}
+
+// universe returns the set of all values of a Kind.
+func universe(kind Kind) (Set) {
+	switch kind {
+	case KindInteger, KindNumber, KindDate:
+		return newIntervalSet(kind, interval{bound{math.Inf(-1), true}, bound{math.Inf(1), true}})
+	case KindBoolean:
+		return &valueSet{kind, map[string]bool{`true`: true, `false`: true}, false}
+	default:
+		return &valueSet{kind, map[string]bool{}, true}
+	}
+}
+
+// newSet converts parsed unary tests to the Set of values they accept.
+func newSet(ut *feel.UnaryTests, kind Kind) (Set, error) {
+
+	all := universe(kind)
+
+	if ut.Any {
+		return all, nil
+	}
+
+	var set Set
+
+	for _, test := range ut.Tests {
+
+		ts, err := testSet(test, kind)
+
+		if err != nil {
+			return nil, err
+		} else if set == nil {
+			set = ts
+		} else {
+			set = set.Union(ts)
+		}
+	}
+
+	if ut.Not {
+		return all.Subtract(set), nil
+	}
+
+	return set, nil
+}
+
+// testSet converts a single comparison or interval to a Set.
+func testSet(test feel.Test, kind Kind) (Set, error) {
+
+	switch kind {
+
+	case KindInteger, KindNumber, KindDate:
+
+		switch test := test.(type) {
+
+		case *feel.Comparison:
+
+			v, err := numericValue(test.Endpoint, kind)
+
+			if err != nil {
+				return nil, err
+			}
+
+			inf := math.Inf(1)
+
+			switch test.Op {
+			case `=`:
+				return newIntervalSet(kind, interval{bound{v, false}, bound{v, false}}), nil
+			case `<`:
+				return newIntervalSet(kind, interval{bound{-inf, true}, bound{v, true}}), nil
+			case `<=`:
+				return newIntervalSet(kind, interval{bound{-inf, true}, bound{v, false}}), nil
+			case `>`:
+				return newIntervalSet(kind, interval{bound{v, true}, bound{inf, true}}), nil
+			case `>=`:
+				return newIntervalSet(kind, interval{bound{v, false}, bound{inf, true}}), nil
+			}
+
+		case *feel.Interval:
+
+			if lo, err := numericValue(test.Start, kind); err != nil {
+				return nil, err
+			} else if hi, err := numericValue(test.End, kind); err != nil {
+				return nil, err
+			} else {
+				return newIntervalSet(kind, interval{bound{lo, test.OpenStart}, bound{hi, test.OpenEnd}}), nil
+			}
+		}
+
+	default:
+
+		if cmp, ok := test.(*feel.Comparison); ok && cmp.Op == `=` {
+
+			switch ep := cmp.Endpoint.(type) {
+			case *feel.StringLit:
+				if kind == KindString {
+					return &valueSet{kind, map[string]bool{ep.String(): true}, false}, nil
+				}
+			case *feel.BoolLit:
+				if kind == KindBoolean {
+					return &valueSet{kind, map[string]bool{ep.String(): true}, false}, nil
+				}
+			}
+		}
+	}
+
+	return nil, fmt.Errorf(`cannot analyze unary test %s at %s`, test, test.Pos())
+}
+
+// numericValue converts a literal endpoint to a point on the number line;
+// dates are represented as seconds since the Unix epoch.
+func numericValue(ep feel.Expr, kind Kind) (float64, error) {
+
+	switch ep := ep.(type) {
+	case *feel.NumberLit:
+		if kind != KindDate {
+			return ep.Value, nil
+		}
+	case *feel.TemporalLit:
+		if t, ok := ep.Value.(time.Time); ok && kind == KindDate {
+			return float64(t.Unix()), nil
+		}
+	}
+
+	return 0, fmt.Errorf(`cannot analyze endpoint %s at %s`, ep, ep.Pos())
+}
+
+// ------------------------------------------------------------------------
+// intervalSet.
+// ------------------------------------------------------------------------
+
+// bound is an interval endpoint. Infinite bounds are always open.
+type bound struct {
+	v			float64
+	open			bool
+}
+
+// interval is a range of numbers between two bounds.
+type interval struct {
+	lo			bound
+	hi			bound
+}
+
+// intervalSet is a union of disjoint intervals, sorted in ascending order.
+// Integer sets use closed bounds on whole numbers so that adjacent ranges
+// such as [1..5] and [6..10] are recognized as contiguous.
+type intervalSet struct {
+	kind			Kind
+	ivs			[]interval
+}
+
+func newIntervalSet(kind Kind, ivs ...interval) (*intervalSet) {
+	this := &intervalSet{kind, ivs}
+	this.normalize()
+	return this
+}
+
+func (this interval) empty() (bool) {
+	return this.lo.v > this.hi.v || (this.lo.v == this.hi.v && (this.lo.open || this.hi.open))
+}
+
+// normalize sorts and merges the intervals of the set.
+func (this *intervalSet) normalize() {
+
+	var ivs []interval
+
+	for _, iv := range this.ivs {
+
+		if this.kind == KindInteger {
+			if !math.IsInf(iv.lo.v, 0) {
+				if iv.lo.open && iv.lo.v == math.Floor(iv.lo.v) {
+					iv.lo = bound{iv.lo.v + 1, false}
+				} else {
+					iv.lo = bound{math.Ceil(iv.lo.v), false}
+				}
+			}
+			if !math.IsInf(iv.hi.v, 0) {
+				if iv.hi.open && iv.hi.v == math.Ceil(iv.hi.v) {
+					iv.hi = bound{iv.hi.v - 1, false}
+				} else {
+					iv.hi = bound{math.Floor(iv.hi.v), false}
+				}
+			}
+		}
+
+		if !iv.empty() {
+			ivs = append(ivs, iv)
+		}
+	}
+
+	sort.Slice(ivs, func(i, j int) bool {
+		if ivs[i].lo.v != ivs[j].lo.v {
+			return ivs[i].lo.v < ivs[j].lo.v
+		}
+		return !ivs[i].lo.open && ivs[j].lo.open
+	})
+
+	this.ivs = nil
+
+	for _, iv := range ivs {
+
+		if n := len(this.ivs); n > 0 && this.adjoins(this.ivs[n-1], iv) {
+			last := &this.ivs[n-1]
+			if iv.hi.v > last.hi.v || (iv.hi.v == last.hi.v && !iv.hi.open) {
+				last.hi = iv.hi
+			}
+		} else {
+			this.ivs = append(this.ivs, iv)
+		}
+	}
+}
+
+// adjoins reports whether b, which starts no earlier than a, overlaps or
+// touches a so that the two can be merged.
+func (this *intervalSet) adjoins(a, b interval) (bool) {
+
+	if this.kind == KindInteger && !math.IsInf(a.hi.v, 0) {
+		return b.lo.v <= a.hi.v+1
+	}
+
+	return b.lo.v < a.hi.v || (b.lo.v == a.hi.v && !(a.hi.open && b.lo.open))
+}
+
+// complement returns the values not in the set.
+func (this *intervalSet) complement() (*intervalSet) {
+
+	var ivs []interval
+
+	prev := bound{math.Inf(-1), true}
+
+	for _, iv := range this.ivs {
+		ivs = append(ivs, interval{prev, bound{iv.lo.v, !iv.lo.open}})
+		prev = bound{iv.hi.v, !iv.hi.open}
+	}
+
+	ivs = append(ivs, interval{prev, bound{math.Inf(1), true}})
+
+	for i := range ivs {
+		if math.IsInf(ivs[i].lo.v, 0) {
+			ivs[i].lo.open = true
+		}
+		if math.IsInf(ivs[i].hi.v, 0) {
+			ivs[i].hi.open = true
+		}
+	}
+
+	return newIntervalSet(this.kind, ivs...)
+}
+
+// Union implements the Set interface for intervalSet.
+func (this *intervalSet) Union(s Set) (Set) {
+	other := s.(*intervalSet)
+	ivs := append(append([]interval{}, this.ivs...), other.ivs...)
+	return newIntervalSet(this.kind, ivs...)
+}
+
+// Intersect implements the Set interface for intervalSet.
+func (this *intervalSet) Intersect(s Set) (Set) {
+
+	var ivs []interval
+
+	for _, a := range this.ivs {
+		for _, b := range s.(*intervalSet).ivs {
+
+			iv := interval{a.lo, a.hi}
+
+			if b.lo.v > iv.lo.v || (b.lo.v == iv.lo.v && b.lo.open) {
+				iv.lo = b.lo
+			}
+			if b.hi.v < iv.hi.v || (b.hi.v == iv.hi.v && b.hi.open) {
+				iv.hi = b.hi
+			}
+
+			ivs = append(ivs, iv)
+		}
+	}
+
+	return newIntervalSet(this.kind, ivs...)
+}
+
+// Subtract implements the Set interface for intervalSet.
+func (this *intervalSet) Subtract(s Set) (Set) {
+	return this.Intersect(s.(*intervalSet).complement())
+}
+
+// Empty implements the Set interface for intervalSet.
+func (this *intervalSet) Empty() (bool) {
+	return len(this.ivs) == 0
+}
+
+// String implements the Set interface for intervalSet.
+func (this *intervalSet) String() (string) {
+
+	var tests []string
+
+	for _, iv := range this.ivs {
+
+		lo, hi := this.format(iv.lo.v), this.format(iv.hi.v)
+
+		switch {
+
+		case math.IsInf(iv.lo.v, 0) && math.IsInf(iv.hi.v, 0):
+			return `-`
+
+		case math.IsInf(iv.lo.v, 0) && iv.hi.open:
+			tests = append(tests, `< ` + hi)
+
+		case math.IsInf(iv.lo.v, 0):
+			tests = append(tests, `<= ` + hi)
+
+		case math.IsInf(iv.hi.v, 0) && iv.lo.open:
+			tests = append(tests, `> ` + lo)
+
+		case math.IsInf(iv.hi.v, 0):
+			tests = append(tests, `>= ` + lo)
+
+		case iv.lo.v == iv.hi.v:
+			tests = append(tests, lo)
+
+		default:
+			start, end := `[`, `]`
+			if iv.lo.open {
+				start = `]`
+			}
+			if iv.hi.open {
+				end = `[`
+			}
+			tests = append(tests, fmt.Sprintf(`%s%s..%s%s`, start, lo, hi, end))
+		}
+	}
+
+	return strings.Join(tests, `, `)
+}
+
+// format renders a point on the number line as a FEEL literal.
+func (this *intervalSet) format(v float64) (string) {
+
+	if this.kind != KindDate {
+		return strconv.FormatFloat(v, 'f', -1, 64)
+	}
+
+	t := time.Unix(int64(v), 0).UTC()
+
+	if t.Hour() == 0 && t.Minute() == 0 && t.Second() == 0 {
+		return fmt.Sprintf(`date(%q)`, t.Format(`2006-01-02`))
+	}
+
+	return fmt.Sprintf(`date and time(%q)`, t.Format(`2006-01-02T15:04:05`))
+}
+
+// ------------------------------------------------------------------------
+// valueSet.
+// ------------------------------------------------------------------------
+
+// valueSet is a finite set of string or boolean literals or, when co is
+// set, the complement of one. Values are keyed by their FEEL source text.
+type valueSet struct {
+	kind			Kind
+	values			map[string]bool
+	co			bool
+}
+
+func (this *valueSet) with(values map[string]bool, co bool) (Set) {
+
+	if this.kind == KindBoolean && co {
+		all := universe(KindBoolean).(*valueSet).values
+		for v := range values {
+			delete(all, v)
+		}
+		values, co = all, false
+	}
+
+	return &valueSet{this.kind, values, co}
+}
+
+// Union implements the Set interface for valueSet.
+func (this *valueSet) Union(s Set) (Set) {
+
+	other := s.(*valueSet)
+
+	switch {
+	case !this.co && !other.co:
+		return this.with(union(this.values, other.values), false)
+	case this.co && other.co:
+		return this.with(intersect(this.values, other.values), true)
+	case this.co:
+		return this.with(subtract(this.values, other.values), true)
+	default:
+		return this.with(subtract(other.values, this.values), true)
+	}
+}
+
+// Intersect implements the Set interface for valueSet.
+func (this *valueSet) Intersect(s Set) (Set) {
+
+	other := s.(*valueSet)
+
+	switch {
+	case !this.co && !other.co:
+		return this.with(intersect(this.values, other.values), false)
+	case this.co && other.co:
+		return this.with(union(this.values, other.values), true)
+	case this.co:
+		return this.with(subtract(other.values, this.values), false)
+	default:
+		return this.with(subtract(this.values, other.values), false)
+	}
+}
+
+// Subtract implements the Set interface for valueSet.
+func (this *valueSet) Subtract(s Set) (Set) {
+	other := s.(*valueSet)
+	return this.Intersect(&valueSet{other.kind, other.values, !other.co})
+}
+
+// Empty implements the Set interface for valueSet.
+func (this *valueSet) Empty() (bool) {
+	return !this.co && len(this.values) == 0
+}
+
+// String implements the Set interface for valueSet.
+func (this *valueSet) String() (string) {
+
+	var values []string
+
+	for v := range this.values {
+		values = append(values, v)
+	}
+
+	sort.Strings(values)
+	list := strings.Join(values, `, `)
+
+	switch {
+	case this.co && list == ``:
+		return `-`
+	case this.co:
+		return `not(` + list + `)`
+	default:
+		return list
+	}
+}
+
+func union(a, b map[string]bool) (map[string]bool) {
+	m := make(map[string]bool)
+	for v := range a {
+		m[v] = true
+	}
+	for v := range b {
+		m[v] = true
+	}
+	return m
+}
+
+func intersect(a, b map[string]bool) (map[string]bool) {
+	m := make(map[string]bool)
+	for v := range a {
+		if b[v] {
+			m[v] = true
+		}
+	}
+	return m
+}
+
+func subtract(a, b map[string]bool) (map[string]bool) {
+	m := make(map[string]bool)
+	for v := range a {
+		if !b[v] {
+			m[v] = true
+		}
+	}
+	return m
+}
@@ -0,0 +1,157 @@
+package api
+
+import (
+	`fmt`
+	`io/ioutil`
+	`net/http`
+	`os`
+	`strings`
+	`sync`
+	`time`
+)
+
+// ------------------------------------------------------------------------
+// Credentials.
+// ------------------------------------------------------------------------
+
+// Credentials authenticate requests to the REST API.
+type Credentials interface {
+	Apply(req *http.Request) (error)
+}
+
+// Refresher is implemented by Credentials that can be reloaded. When a
+// request is rejected with 401 Unauthorized, the client refreshes the
+// credentials and retries the request once.
+type Refresher interface {
+	Refresh() (error)
+}
+
+// WithCredentials makes the client authenticate every request.
+func WithCredentials(creds Credentials) (Option) {
+	return func(this *dmnApi) {
+		this.creds = creds
+	}
+}
+
+// ------------------------------------------------------------------------
+// Basic Authentication.
+// ------------------------------------------------------------------------
+
+type basicAuth struct {
+	user			string
+	password		string
+}
+
+// BasicAuth returns Credentials for HTTP basic authentication, as used by
+// the Camunda REST API basic authentication filter.
+func BasicAuth(user, password string) (Credentials) {
+	return &basicAuth{user, password}
+}
+
+func (this *basicAuth) Apply(req *http.Request) (error) {
+	req.SetBasicAuth(this.user, this.password)
+	return nil
+}
+
+// ------------------------------------------------------------------------
+// Bearer Tokens.
+// ------------------------------------------------------------------------
+
+type bearerToken struct {
+	token			string
+}
+
+// BearerToken returns Credentials that send a static bearer token.
+func BearerToken(token string) (Credentials) {
+	return &bearerToken{strings.TrimSpace(token)}
+}
+
+func (this *bearerToken) Apply(req *http.Request) (error) {
+	req.Header.Set(`Authorization`, `Bearer ` + this.token)
+	return nil
+}
+
+// tokenFile reads a bearer token from a file, rereading it whenever the
+// file changes, so that a token rotated by an external agent is picked up
+// without restarting the client.
+type tokenFile struct {
+	mutex			sync.Mutex
+	path			string
+	token			string
+	modTime			time.Time
+}
+
+// TokenFile returns Credentials that send the bearer token in a file.
+func TokenFile(path string) (Credentials) {
+	return &tokenFile{path: path}
+}
+
+func (this *tokenFile) Apply(req *http.Request) (error) {
+
+	this.mutex.Lock()
+	defer this.mutex.Unlock()
+
+	if fi, err := os.Stat(this.path); err != nil {
+		return err
+	} else if this.token == `` || !fi.ModTime().Equal(this.modTime) {
+		if err := this.load(); err != nil {
+			return err
+		}
+	}
+
+	req.Header.Set(`Authorization`, `Bearer ` + this.token)
+	return nil
+}
+
+func (this *tokenFile) Refresh() (error) {
+
+	this.mutex.Lock()
+	defer this.mutex.Unlock()
+
+	return this.load()
+}
+
+// load reads the token file. The caller must hold the mutex.
+func (this *tokenFile) load() (error) {
+
+	fi, err := os.Stat(this.path)
+
+	if err != nil {
+		return err
+	}
+
+	b, err := ioutil.ReadFile(this.path)
+
+	if err != nil {
+		return err
+	}
+
+	if this.token = strings.TrimSpace(string(b)); this.token == `` {
+		return fmt.Errorf(`token file %s is empty`, this.path)
+	}
+
+	this.modTime = fi.ModTime()
+	return nil
+}
+
+// tokenEnv reads a bearer token from an environment variable on every
+// request.
+type tokenEnv struct {
+	name			string
+}
+
+// TokenEnv returns Credentials that send the bearer token in an
+// environment variable.
+func TokenEnv(name string) (Credentials) {
+	return &tokenEnv{name}
+}
+
+func (this *tokenEnv) Apply(req *http.Request) (error) {
+
+	if token := strings.TrimSpace(os.Getenv(this.name)); token == `` {
+		return fmt.Errorf(`environment variable %s is not set`, this.name)
+	} else {
+		req.Header.Set(`Authorization`, `Bearer ` + token)
+		return nil
+	}
+}
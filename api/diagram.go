@@ -0,0 +1,63 @@
+package api
+
+import (
+	`context`
+	`errors`
+	`net/http`
+	`github.com/jscherff/dmnsdk/model`
+)
+
+// ErrNoDiagram is returned for a decision definition that was deployed
+// without a diagram image.
+var ErrNoDiagram = errors.New(`decision definition has no diagram`)
+
+// ------------------------------------------------------------------------
+// Diagrams.
+// ------------------------------------------------------------------------
+
+// diagram retrieves the diagram image from a diagram endpoint. The engine
+// answers 204 No Content when the definition has no diagram.
+func (this *dmnApi) diagram(ctx context.Context, url string) (*model.DmnDiagram, error) {
+
+	resp, err := this.exchange(ctx, http.MethodGet, url, `*/*`, nil)
+
+	if err != nil {
+		return nil, err
+	} else if resp.StatusCode == http.StatusNoContent || resp.Body.Len() == 0 {
+		return nil, ErrNoDiagram
+	}
+
+	return &model.DmnDiagram{
+		ContentType: resp.Header.Get(`Content-Type`),
+		Data: resp.Body.Bytes(),
+	}, nil
+}
+
+func (this *dmnApi) DmnDiagramById(id string) (*model.DmnDiagram, error) {
+	return this.DmnDiagramByIdContext(context.Background(), id)
+}
+
+func (this *dmnApi) DmnDiagramByKey(key string) (*model.DmnDiagram, error) {
+	return this.DmnDiagramByKeyContext(context.Background(), key)
+}
+
+func (this *dmnApi) DmnDiagramByKeyTenant(key, tenantId string) (*model.DmnDiagram, error) {
+	return this.DmnDiagramByKeyTenantContext(context.Background(), key, tenantId)
+}
+
+func (this *dmnApi) DmnDiagramByIdContext(ctx context.Context, id string) (*model.DmnDiagram, error) {
+	return this.diagram(ctx, this.Server + epDmnDiagramById.With(id))
+}
+
+func (this *dmnApi) DmnDiagramByKeyContext(ctx context.Context, key string) (*model.DmnDiagram, error) {
+	return this.diagram(ctx, this.Server + epDmnDiagramByKey.With(key))
+}
+
+func (this *dmnApi) DmnDiagramByKeyTenantContext(ctx context.Context, key, tenantId string) (*model.DmnDiagram, error) {
+
+	if tenantId == `` {
+		return this.DmnDiagramByKeyContext(ctx, key)
+	}
+
+	return this.diagram(ctx, this.Server + epDmnDiagramByKeyTenant.With(key, tenantId))
+}
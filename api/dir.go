@@ -0,0 +1,714 @@
+package api
+
+import (
+	`context`
+	`encoding/json`
+	`errors`
+	`fmt`
+	`io/ioutil`
+	`mime`
+	`os`
+	`path/filepath`
+	`regexp`
+	`sort`
+	`strconv`
+	`strings`
+	`sync`
+	`github.com/jscherff/dmnsdk/model`
+)
+
+// =============================================================================
+// Snapshot Directory Layout
+//
+//	File				Contents
+//	----				--------
+//	dmnlist.json			Decision definition list (DmnList).
+//	dmnxml_key_<key>_ver_<n>.xml	DMN XML by key and version.
+//	dmnxml_id_<id>.xml		DMN XML by id.
+//	dmn_key_<key>_ver_<n>.json	Parsed DMN (Dmn.Json) by key and version.
+//	dmn_id_<id>.json		Parsed DMN (Dmn.Json) by id.
+//	dmndiagram_id_<id>.<ext>	Diagram image by id, if deployed.
+//
+// The list file is optional. Without it the list is reconstructed from
+// the key and version files, and ids are recovered by matching the content
+// of the id files. Key and version files are only written for definitions
+// that belong to no tenant, since keys are not unique across tenants;
+// definitions of a tenant are found through the list and their id files.
+// -----------------------------------------------------------------------------
+
+const (
+	FileDmnList		= `dmnlist.json`
+	FileXmlByKeyVer		= `dmnxml_key_%s_ver_%d.xml`
+	FileXmlById		= `dmnxml_id_%s.xml`
+	FileDmnByKeyVer		= `dmn_key_%s_ver_%d.json`
+	FileDmnById		= `dmn_id_%s.json`
+	FileDiagramById		= `dmndiagram_id_%s%s`
+)
+
+// ErrReadOnly is returned for updates to a snapshot directory.
+var ErrReadOnly = errors.New(`snapshot directory is read-only`)
+
+var (
+	reXmlByKeyVer = regexp.MustCompile(`^dmnxml_key_(.+)_ver_(\d+)\.xml$`)
+	reXmlById = regexp.MustCompile(`^dmnxml_id_(.+)\.xml$`)
+)
+
+type dmnDirApi struct {
+	Dir string
+	listMutex sync.Mutex
+	dmnList *model.DmnList
+	mapMutex sync.Mutex
+	dmnMap model.DmnMap
+}
+
+// NewDmnDirApi returns a DmnApi that serves decision definitions from a
+// snapshot directory written by dmnsave.
+func NewDmnDirApi(dir string) (DmnApi) {
+	return &dmnDirApi{Dir: dir}
+}
+
+func (this *dmnDirApi) path(format string, args ...interface{}) (string) {
+	return filepath.Join(this.Dir, fmt.Sprintf(format, args...))
+}
+
+func (this *dmnDirApi) DmnList() (*model.DmnList, error) {
+
+	this.listMutex.Lock()
+	defer this.listMutex.Unlock()
+
+	if this.dmnList != nil {
+		return this.dmnList, nil
+	}
+
+	var (
+		dl *model.DmnList
+		err error
+	)
+
+	if path := this.path(FileDmnList); fileExists(path) {
+		if dl, err = model.NewDmnList(path); err != nil {
+			err = &DecodeError{path, err}
+		}
+	} else {
+		dl, err = this.scan()
+	}
+
+	if err != nil {
+		return nil, err
+	}
+
+	this.dmnList = dl
+	return this.dmnList, nil
+}
+
+// scan reconstructs the decision definition list from the key and version
+// XML files of the snapshot directory.
+func (this *dmnDirApi) scan() (*model.DmnList, error) {
+
+	files, err := ioutil.ReadDir(this.Dir)
+
+	if err != nil {
+		return nil, err
+	}
+
+	ids := make(map[string][]string)
+	dl := make(model.DmnList, 0)
+
+	for _, fi := range files {
+		if m := reXmlById.FindStringSubmatch(fi.Name()); m != nil {
+			if b, err := ioutil.ReadFile(filepath.Join(this.Dir, fi.Name())); err != nil {
+				return nil, err
+			} else {
+				ids[string(b)] = append(ids[string(b)], m[1])
+			}
+		}
+	}
+
+	for _, list := range ids {
+		sort.Strings(list)
+	}
+
+	for _, fi := range files {
+
+		m := reXmlByKeyVer.FindStringSubmatch(fi.Name())
+
+		if m == nil {
+			continue
+		}
+
+		key := m[1]
+		ver, _ := strconv.Atoi(m[2])
+
+		b, err := ioutil.ReadFile(filepath.Join(this.Dir, fi.Name()))
+
+		if err != nil {
+			return nil, err
+		}
+
+		di := &model.DmnInfo{Id: fmt.Sprintf(`%s:%d`, key, ver), Key: key, Version: ver}
+
+		// A resource with several decisions is saved under the id of
+		// each, so match the id of this key.
+
+		list := ids[string(b)]
+
+		for i, id := range list {
+			if strings.HasPrefix(id, key + `:`) {
+				di.Id, ids[string(b)] = id, append(list[:i:i], list[i+1:]...)
+				break
+			}
+		}
+
+		if dmn, err := model.NewDmn(string(b)); err == nil {
+			di.Category = dmn.Namespace
+			if d, err := dmn.Decision(key); err == nil {
+				di.Name = d.Name
+			}
+		}
+
+		dl = append(dl, di)
+	}
+
+	return &dl, nil
+}
+
+func (this *dmnDirApi) DmnListWhere(q *DmnQuery) (*model.DmnList, error) {
+
+	if q == nil {
+		return this.DmnList()
+	} else if err := q.Validate(); err != nil {
+		return nil, err
+	} else if dl, err := this.DmnList(); err != nil {
+		return nil, err
+	} else {
+		result := q.Filter(*dl)
+		return &result, nil
+	}
+}
+
+func (this *dmnDirApi) DmnCount(q *DmnQuery) (int, error) {
+
+	if dl, err := this.DmnList(); err != nil {
+		return 0, err
+	} else if q == nil {
+		return len(*dl), nil
+	} else {
+		return q.Count(*dl), nil
+	}
+}
+
+func (this *dmnDirApi) DmnIter(q *DmnQuery) (*DmnIterator) {
+	return this.DmnIterContext(context.Background(), q)
+}
+
+func (this *dmnDirApi) DmnMap() (model.DmnMap, error) {
+
+	this.mapMutex.Lock()
+	defer this.mapMutex.Unlock()
+
+	if this.dmnMap != nil {
+		return this.dmnMap, nil
+	}
+
+	if dl, err := this.DmnList(); err != nil {
+		return nil, err
+	} else if dm, err := dl.Map(); err != nil {
+		return nil, err
+	} else {
+		this.dmnMap = dm
+		return this.dmnMap, nil
+	}
+}
+
+func (this *dmnDirApi) DmnInfoById(id string) (*model.DmnInfo, error) {
+
+	if dl, err := this.DmnList(); err != nil {
+		return nil, err
+	} else {
+		for _, di := range *dl {
+			if di.Id == id {
+				return di, nil
+			}
+		}
+	}
+
+	return nil, notFound(this.Dir, `id %s not found`, id)
+}
+
+func (this *dmnDirApi) DmnInfoByKey(key string) (*model.DmnInfo, error) {
+	return this.DmnInfoByKeyTenant(key, ``)
+}
+
+func (this *dmnDirApi) DmnInfoByKeyTenant(key, tenantId string) (*model.DmnInfo, error) {
+
+	if dm, err := this.DmnMap(); err != nil {
+		return nil, err
+	} else if di, err := dm.Latest(key, tenantId); err != nil {
+		return nil, notFound(this.Dir, `%v`, err)
+	} else {
+		return di, nil
+	}
+}
+
+func (this *dmnDirApi) DmnInfoByKeyVer(key string, ver int) (*model.DmnInfo, error) {
+	return this.DmnInfoByKeyVerTenant(key, ver, ``)
+}
+
+func (this *dmnDirApi) DmnInfoByKeyVerTenant(key string, ver int, tenantId string) (*model.DmnInfo, error) {
+
+	if dm, err := this.DmnMap(); err != nil {
+		return nil, err
+	} else if di, err := dm.DmnInfoTenant(key, ver, tenantId); err != nil {
+		return nil, notFound(this.Dir, `%v`, err)
+	} else {
+		return di, nil
+	}
+}
+
+func (this *dmnDirApi) DmnXmlById(id string) (*model.DmnXml, error) {
+
+	if path := this.path(FileXmlById, id); fileExists(path) {
+		return this.readXml(id, path)
+	} else if di, err := this.DmnInfoById(id); err != nil {
+		return nil, err
+	} else if di.TenantId != `` {
+		return nil, notFound(path, `id %s not found`, id)
+	} else {
+		return this.readXml(id, this.path(FileXmlByKeyVer, di.Key, di.Version))
+	}
+}
+
+func (this *dmnDirApi) DmnXmlByKey(key string) (*model.DmnXml, error) {
+	return this.DmnXmlByKeyTenant(key, ``)
+}
+
+func (this *dmnDirApi) DmnXmlByKeyTenant(key, tenantId string) (*model.DmnXml, error) {
+
+	if di, err := this.DmnInfoByKeyTenant(key, tenantId); err != nil {
+		return nil, err
+	} else {
+		return this.DmnXmlByKeyVerTenant(di.Key, di.Version, tenantId)
+	}
+}
+
+func (this *dmnDirApi) DmnXmlByKeyVer(key string, ver int) (*model.DmnXml, error) {
+	return this.DmnXmlByKeyVerTenant(key, ver, ``)
+}
+
+func (this *dmnDirApi) DmnXmlByKeyVerTenant(key string, ver int, tenantId string) (*model.DmnXml, error) {
+
+	if di, err := this.DmnInfoByKeyVerTenant(key, ver, tenantId); err != nil {
+		return nil, err
+	} else if path := this.path(FileXmlByKeyVer, key, ver); tenantId == `` && fileExists(path) {
+		return this.readXml(di.Id, path)
+	} else {
+		return this.readXml(di.Id, this.path(FileXmlById, di.Id))
+	}
+}
+
+// readXml reads a DMN XML file into a DmnXml object.
+func (this *dmnDirApi) readXml(id, path string) (*model.DmnXml, error) {
+
+	if b, err := ioutil.ReadFile(path); os.IsNotExist(err) {
+		return nil, notFound(path, `id %s not found`, id)
+	} else if err != nil {
+		return nil, err
+	} else {
+		return &model.DmnXml{Id: id, DmnXml: string(b)}, nil
+	}
+}
+
+func (this *dmnDirApi) DmnById(id string) (*model.Dmn, error) {
+
+	if dx, err := this.DmnXmlById(id); err == nil {
+		return this.parseXml(dx)
+	} else if path := this.path(FileDmnById, id); fileExists(path) {
+		return this.readDmn(path)
+	} else {
+		return nil, err
+	}
+}
+
+func (this *dmnDirApi) DmnByKey(key string) (*model.Dmn, error) {
+	return this.DmnByKeyTenant(key, ``)
+}
+
+func (this *dmnDirApi) DmnByKeyTenant(key, tenantId string) (*model.Dmn, error) {
+
+	if di, err := this.DmnInfoByKeyTenant(key, tenantId); err != nil {
+		return nil, err
+	} else {
+		return this.DmnByKeyVerTenant(di.Key, di.Version, tenantId)
+	}
+}
+
+func (this *dmnDirApi) DmnByKeyVer(key string, ver int) (*model.Dmn, error) {
+	return this.DmnByKeyVerTenant(key, ver, ``)
+}
+
+func (this *dmnDirApi) DmnByKeyVerTenant(key string, ver int, tenantId string) (*model.Dmn, error) {
+
+	dx, err := this.DmnXmlByKeyVerTenant(key, ver, tenantId)
+
+	if err == nil {
+		return this.parseXml(dx)
+	}
+
+	// Fall back to the parsed DMN files.
+
+	if path := this.path(FileDmnByKeyVer, key, ver); tenantId == `` && fileExists(path) {
+		return this.readDmn(path)
+	}
+
+	if di, e := this.DmnInfoByKeyVerTenant(key, ver, tenantId); e == nil {
+		if path := this.path(FileDmnById, di.Id); fileExists(path) {
+			return this.readDmn(path)
+		}
+	}
+
+	return nil, err
+}
+
+func (this *dmnDirApi) DmnDiagramById(id string) (*model.DmnDiagram, error) {
+
+	if _, err := this.DmnInfoById(id); err != nil {
+		return nil, err
+	}
+
+	files, err := ioutil.ReadDir(this.Dir)
+
+	if err != nil {
+		return nil, err
+	}
+
+	prefix := fmt.Sprintf(FileDiagramById, id, `.`)
+
+	for _, fi := range files {
+
+		if fi.IsDir() || !strings.HasPrefix(fi.Name(), prefix) {
+			continue
+		}
+
+		if b, err := ioutil.ReadFile(filepath.Join(this.Dir, fi.Name())); err != nil {
+			return nil, err
+		} else {
+			ct := mime.TypeByExtension(filepath.Ext(fi.Name()))
+			return &model.DmnDiagram{ContentType: ct, Data: b}, nil
+		}
+	}
+
+	return nil, ErrNoDiagram
+}
+
+func (this *dmnDirApi) DmnDiagramByKey(key string) (*model.DmnDiagram, error) {
+	return this.DmnDiagramByKeyTenant(key, ``)
+}
+
+func (this *dmnDirApi) DmnDiagramByKeyTenant(key, tenantId string) (*model.DmnDiagram, error) {
+
+	if di, err := this.DmnInfoByKeyTenant(key, tenantId); err != nil {
+		return nil, err
+	} else {
+		return this.DmnDiagramById(di.Id)
+	}
+}
+
+// A snapshot records the definitions as they were saved, so it cannot be
+// updated.
+
+func (this *dmnDirApi) UpdateHistoryTtlById(id string, ttl *int) (error) {
+	return ErrReadOnly
+}
+
+func (this *dmnDirApi) UpdateHistoryTtlByKey(key string, ttl *int) (error) {
+	return ErrReadOnly
+}
+
+func (this *dmnDirApi) UpdateHistoryTtlByKeyTenant(key, tenantId string, ttl *int) (error) {
+	return ErrReadOnly
+}
+
+// The snapshot directory has no engine, so decisions are evaluated
+// locally with Dmn.EvaluateDecision. The decision of a definition is the
+// one whose id is the definition key; the decisions it requires are
+// evaluated first.
+
+func (this *dmnDirApi) Evaluate(id string, vars map[string]interface{}) ([]model.ResultEntry, error) {
+
+	if di, err := this.DmnInfoById(id); err != nil {
+		return nil, err
+	} else if dmn, err := this.DmnById(id); err != nil {
+		return nil, err
+	} else {
+		return evaluateLocal(dmn, di.Key, vars)
+	}
+}
+
+func (this *dmnDirApi) EvaluateByKey(key string, vars map[string]interface{}) ([]model.ResultEntry, error) {
+	return this.EvaluateByKeyTenant(key, ``, vars)
+}
+
+func (this *dmnDirApi) EvaluateByKeyTenant(key, tenantId string, vars map[string]interface{}) ([]model.ResultEntry, error) {
+
+	if dmn, err := this.DmnByKeyTenant(key, tenantId); err != nil {
+		return nil, err
+	} else {
+		return evaluateLocal(dmn, key, vars)
+	}
+}
+
+// evaluateLocal evaluates a decision of a DMN with variables given as Go
+// values or as typed variables.
+func evaluateLocal(dmn *model.Dmn, key string, vars map[string]interface{}) ([]model.ResultEntry, error) {
+
+	gv := make(map[string]interface{})
+
+	for name, val := range vars {
+		if v, ok := val.(*Variable); !ok {
+			gv[name] = val
+		} else if val, err := v.GoValue(); err != nil {
+			return nil, fmt.Errorf(`variable %s: %v`, name, err)
+		} else {
+			gv[name] = val
+		}
+	}
+
+	if dr, err := dmn.EvaluateDecision(key, gv); err != nil {
+		return nil, err
+	} else {
+		return dr.Entries, nil
+	}
+}
+
+// parseXml parses the DMN XML of a DmnXml object.
+func (this *dmnDirApi) parseXml(dx *model.DmnXml) (*model.Dmn, error) {
+
+	if dmn, err := model.NewDmn(dx.DmnXml); err != nil {
+		return nil, &DecodeError{this.path(FileXmlById, dx.Id), err}
+	} else {
+		return dmn, nil
+	}
+}
+
+// readDmn reads a parsed DMN saved in JSON format.
+func (this *dmnDirApi) readDmn(path string) (*model.Dmn, error) {
+
+	if fh, err := os.Open(path); err != nil {
+		return nil, err
+	} else {
+		defer fh.Close()
+		dmn := new(model.Dmn)
+		if err := json.NewDecoder(fh).Decode(dmn); err != nil {
+			return nil, &DecodeError{path, err}
+		}
+		return dmn, nil
+	}
+}
+
+func fileExists(path string) (bool) {
+	fi, err := os.Stat(path)
+	return err == nil && !fi.IsDir()
+}
+
+// The snapshot directory is read from local disk, so the context variants
+// only check the context before delegating.
+
+func (this *dmnDirApi) DmnListContext(ctx context.Context) (*model.DmnList, error) {
+	if err := ctx.Err(); err != nil {
+		return nil, err
+	}
+	return this.DmnList()
+}
+
+func (this *dmnDirApi) DmnListWhereContext(ctx context.Context, q *DmnQuery) (*model.DmnList, error) {
+	if err := ctx.Err(); err != nil {
+		return nil, err
+	}
+	return this.DmnListWhere(q)
+}
+
+func (this *dmnDirApi) DmnCountContext(ctx context.Context, q *DmnQuery) (int, error) {
+	if err := ctx.Err(); err != nil {
+		return 0, err
+	}
+	return this.DmnCount(q)
+}
+
+func (this *dmnDirApi) DmnIterContext(ctx context.Context, q *DmnQuery) (*DmnIterator) {
+
+	fetch := func(q *DmnQuery) (model.DmnList, error) {
+		if dl, err := this.DmnListWhereContext(ctx, q); err != nil {
+			return nil, err
+		} else {
+			return *dl, nil
+		}
+	}
+
+	return newDmnIterator(q, 0, fetch, nil)
+}
+
+func (this *dmnDirApi) DmnMapContext(ctx context.Context) (model.DmnMap, error) {
+	if err := ctx.Err(); err != nil {
+		return nil, err
+	}
+	return this.DmnMap()
+}
+
+func (this *dmnDirApi) DmnInfoByIdContext(ctx context.Context, id string) (*model.DmnInfo, error) {
+	if err := ctx.Err(); err != nil {
+		return nil, err
+	}
+	return this.DmnInfoById(id)
+}
+
+func (this *dmnDirApi) DmnInfoByKeyContext(ctx context.Context, key string) (*model.DmnInfo, error) {
+	if err := ctx.Err(); err != nil {
+		return nil, err
+	}
+	return this.DmnInfoByKey(key)
+}
+
+func (this *dmnDirApi) DmnInfoByKeyVerContext(ctx context.Context, key string, ver int) (*model.DmnInfo, error) {
+	if err := ctx.Err(); err != nil {
+		return nil, err
+	}
+	return this.DmnInfoByKeyVer(key, ver)
+}
+
+func (this *dmnDirApi) DmnXmlByIdContext(ctx context.Context, id string) (*model.DmnXml, error) {
+	if err := ctx.Err(); err != nil {
+		return nil, err
+	}
+	return this.DmnXmlById(id)
+}
+
+func (this *dmnDirApi) DmnXmlByKeyContext(ctx context.Context, key string) (*model.DmnXml, error) {
+	if err := ctx.Err(); err != nil {
+		return nil, err
+	}
+	return this.DmnXmlByKey(key)
+}
+
+func (this *dmnDirApi) DmnXmlByKeyVerContext(ctx context.Context, key string, ver int) (*model.DmnXml, error) {
+	if err := ctx.Err(); err != nil {
+		return nil, err
+	}
+	return this.DmnXmlByKeyVer(key, ver)
+}
+
+func (this *dmnDirApi) DmnByIdContext(ctx context.Context, id string) (*model.Dmn, error) {
+	if err := ctx.Err(); err != nil {
+		return nil, err
+	}
+	return this.DmnById(id)
+}
+
+func (this *dmnDirApi) DmnByKeyContext(ctx context.Context, key string) (*model.Dmn, error) {
+	if err := ctx.Err(); err != nil {
+		return nil, err
+	}
+	return this.DmnByKey(key)
+}
+
+func (this *dmnDirApi) DmnByKeyVerContext(ctx context.Context, key string, ver int) (*model.Dmn, error) {
+	if err := ctx.Err(); err != nil {
+		return nil, err
+	}
+	return this.DmnByKeyVer(key, ver)
+}
+
+func (this *dmnDirApi) DmnInfoByKeyTenantContext(ctx context.Context, key, tenantId string) (*model.DmnInfo, error) {
+	if err := ctx.Err(); err != nil {
+		return nil, err
+	}
+	return this.DmnInfoByKeyTenant(key, tenantId)
+}
+
+func (this *dmnDirApi) DmnInfoByKeyVerTenantContext(ctx context.Context, key string, ver int, tenantId string) (*model.DmnInfo, error) {
+	if err := ctx.Err(); err != nil {
+		return nil, err
+	}
+	return this.DmnInfoByKeyVerTenant(key, ver, tenantId)
+}
+
+func (this *dmnDirApi) DmnXmlByKeyTenantContext(ctx context.Context, key, tenantId string) (*model.DmnXml, error) {
+	if err := ctx.Err(); err != nil {
+		return nil, err
+	}
+	return this.DmnXmlByKeyTenant(key, tenantId)
+}
+
+func (this *dmnDirApi) DmnXmlByKeyVerTenantContext(ctx context.Context, key string, ver int, tenantId string) (*model.DmnXml, error) {
+	if err := ctx.Err(); err != nil {
+		return nil, err
+	}
+	return this.DmnXmlByKeyVerTenant(key, ver, tenantId)
+}
+
+func (this *dmnDirApi) DmnByKeyTenantContext(ctx context.Context, key, tenantId string) (*model.Dmn, error) {
+	if err := ctx.Err(); err != nil {
+		return nil, err
+	}
+	return this.DmnByKeyTenant(key, tenantId)
+}
+
+func (this *dmnDirApi) DmnByKeyVerTenantContext(ctx context.Context, key string, ver int, tenantId string) (*model.Dmn, error) {
+	if err := ctx.Err(); err != nil {
+		return nil, err
+	}
+	return this.DmnByKeyVerTenant(key, ver, tenantId)
+}
+
+func (this *dmnDirApi) EvaluateContext(ctx context.Context, id string, vars map[string]interface{}) ([]model.ResultEntry, error) {
+	if err := ctx.Err(); err != nil {
+		return nil, err
+	}
+	return this.Evaluate(id, vars)
+}
+
+func (this *dmnDirApi) EvaluateByKeyContext(ctx context.Context, key string, vars map[string]interface{}) ([]model.ResultEntry, error) {
+	if err := ctx.Err(); err != nil {
+		return nil, err
+	}
+	return this.EvaluateByKey(key, vars)
+}
+
+func (this *dmnDirApi) EvaluateByKeyTenantContext(ctx context.Context, key, tenantId string, vars map[string]interface{}) ([]model.ResultEntry, error) {
+	if err := ctx.Err(); err != nil {
+		return nil, err
+	}
+	return this.EvaluateByKeyTenant(key, tenantId, vars)
+}
+
+func (this *dmnDirApi) DmnDiagramByIdContext(ctx context.Context, id string) (*model.DmnDiagram, error) {
+	if err := ctx.Err(); err != nil {
+		return nil, err
+	}
+	return this.DmnDiagramById(id)
+}
+
+func (this *dmnDirApi) DmnDiagramByKeyContext(ctx context.Context, key string) (*model.DmnDiagram, error) {
+	if err := ctx.Err(); err != nil {
+		return nil, err
+	}
+	return this.DmnDiagramByKey(key)
+}
+
+func (this *dmnDirApi) DmnDiagramByKeyTenantContext(ctx context.Context, key, tenantId string) (*model.DmnDiagram, error) {
+	if err := ctx.Err(); err != nil {
+		return nil, err
+	}
+	return this.DmnDiagramByKeyTenant(key, tenantId)
+}
+
+func (this *dmnDirApi) UpdateHistoryTtlByIdContext(ctx context.Context, id string, ttl *int) (error) {
+	return ErrReadOnly
+}
+
+func (this *dmnDirApi) UpdateHistoryTtlByKeyContext(ctx context.Context, key string, ttl *int) (error) {
+	return ErrReadOnly
+}
+
+func (this *dmnDirApi) UpdateHistoryTtlByKeyTenantContext(ctx context.Context, key, tenantId string, ttl *int) (error) {
+	return ErrReadOnly
+}
@@ -1,61 +1,55 @@
 package api
 
 import (
+	`context`
+	`crypto/tls`
 	`fmt`
+	`net/http`
+	`net/url`
+	`sync`
+	`time`
 	`github.com/jscherff/dmnsdk/model`
 )
 
 // =============================================================================
 // Get List Parameters
 //
-//	Name			Description
-//	----			-----------
-//	decisionDmnId	Filter by decision definition id.
-//	decisionDmnIdIn	Filter by decision definition ids.
-//	name			Filter by decision definition name.
-//	nameLike		Filter by decision definition name substring.
-//				parameter is a substring of.
-//	deploymentId		Filter by the deployment the id belongs to.
-//	key			Filter by decision definition key.
-//	keyLike			Filter by decision definition key substring.
-//	category		Filter by decision definition category.
-//	categoryLike		Filter by decision definition category substring.
-//	version			Filter by decision definition version.
-//	latestVersion		Only include those decision definitions that
-//				are latest versions. Value may only be true,
-//				as false is the default behavior.
-//	resourceName		Filter by decision definition resource.
-//	resourceNameLike	Filter by decision definition resource substring.
-//	versionTag		Filter by the version tag.
-//	sortBy			Sort the results by a given criterion. Valid
-//				values are category, key, id, name, version,
-//				deploymentId and tenantId. Must be used in
-//				conjunction with the sortOrder parameter.
-//	sortOrder		Sort the results in a given order, asc or desc.
-//	firstResult		Index of the first result to return.
-//	maxResults		Maximum number of results to return.
+//	Name				Description
+//	----				-----------
+//	decisionDefinitionId		Filter by decision definition id.
+//	decisionDefinitionIdIn		Filter by a comma-separated list of
+//					decision definition ids.
+//	name				Filter by decision definition name.
+//	nameLike			Filter by decision definition name pattern.
+//	deploymentId			Filter by the deployment the id belongs to.
+//	key				Filter by decision definition key.
+//	keyLike				Filter by decision definition key pattern.
+//	category			Filter by decision definition category.
+//	categoryLike			Filter by decision definition category pattern.
+//	version				Filter by decision definition version.
+//	latestVersion			Only include those decision definitions that
+//					are latest versions. Value may only be true,
+//					as false is the default behavior.
+//	resourceName			Filter by decision definition resource.
+//	resourceNameLike		Filter by decision definition resource pattern.
+//	versionTag			Filter by the version tag.
+//	tenantIdIn			Filter by a comma-separated list of tenant ids.
+//	withoutTenantId			Only include decision definitions that belong
+//					to no tenant.
+//	sortBy				Sort the results by a given criterion. Valid
+//					values are category, key, id, name, version,
+//					deploymentId, tenantId and versionTag. Must be
+//					used in conjunction with the sortOrder parameter.
+//	sortOrder			Sort the results in a given order, asc or desc.
+//	firstResult			Index of the first result to return.
+//	maxResults			Maximum number of results to return.
 //
 // Get Count Parameters
 //
-//	Name			Description
-//	----			-----------
-//	decisionDmnId	Filter by decision definition id.
-//	decisionDmnIdIn	Filter by decision definition ids.
-//	name			Filter by decision definition name.
-//	nameLike		Filter by decision definition name substring.
-//				parameter is a substring of.
-//	deploymentId		Filter by the deployment the id belongs to.
-//	key			Filter by decision definition key.
-//	keyLike			Filter by decision definition key substring.
-//	category		Filter by decision definition category.
-//	categoryLike		Filter by decision definition category substring.
-//	version			Filter by decision definition version.
-//	latestVersion		Only include those decision definitions that
-//				are latest versions. Value may only be true,
-//				as false is the default behavior.
-//	resourceName		Filter by decision definition resource.
-//	resourceNameLike	Filter by decision definition resource substring.
-//	versionTag		Filter by the version tag.
+//	The filter parameters of Get List, without sorting or paging.
+//
+// Pattern parameters use SQL LIKE syntax: % matches any sequence of
+// characters and _ matches any single character. See DmnQuery.
 // ----------------------------------------------------------------------------- 
 
 type Endpoint string
@@ -71,18 +65,39 @@ const (
 	epDmnXmlByKey Endpoint	= `/key/%s/xml`
 	epDmnById Endpoint	= `/%s`
 	epDmnByKey Endpoint	= `/key/%s`
+	epDmnInfoByKeyTenant Endpoint	= `/key/%s/tenant-id/%s`
+	epDmnXmlByKeyTenant Endpoint	= `/key/%s/tenant-id/%s/xml`
+	epEvaluateById Endpoint	= `/%s/evaluate`
+	epEvaluateByKey Endpoint	= `/key/%s/evaluate`
+	epEvaluateByKeyTenant Endpoint	= `/key/%s/tenant-id/%s/evaluate`
+	epDmnDiagramById Endpoint	= `/%s/diagram`
+	epDmnDiagramByKey Endpoint	= `/key/%s/diagram`
+	epDmnDiagramByKeyTenant Endpoint	= `/key/%s/tenant-id/%s/diagram`
+	epHistoryTtlById Endpoint	= `/%s/history-time-to-live`
+	epHistoryTtlByKey Endpoint	= `/key/%s/history-time-to-live`
+	epHistoryTtlByKeyTenant Endpoint	= `/key/%s/tenant-id/%s/history-time-to-live`
 )
 
 func (this Endpoint) String() (string) {
 	return string(this)
 }
 
-func (this Endpoint) With(param string) (string) {
-	return fmt.Sprintf(string(this), param)
+func (this Endpoint) With(params ...string) (string) {
+
+	args := make([]interface{}, len(params))
+
+	for i, param := range params {
+		args[i] = param
+	}
+
+	return fmt.Sprintf(string(this), args...)
 }
 
 type DmnApi interface {
 	DmnList() (*model.DmnList, error)
+	DmnListWhere(q *DmnQuery) (*model.DmnList, error)
+	DmnCount(q *DmnQuery) (int, error)
+	DmnIter(q *DmnQuery) (*DmnIterator)
 	DmnMap() (model.DmnMap, error)
 	DmnInfoById(id string) (*model.DmnInfo, error)
 	DmnInfoByKey(key string) (*model.DmnInfo, error)
@@ -93,27 +108,204 @@ type DmnApi interface {
 	DmnById(id string) (*model.Dmn, error)
 	DmnByKey(key string) (*model.Dmn, error)
 	DmnByKeyVer(key string, ver int) (*model.Dmn, error)
+
+	DmnInfoByKeyTenant(key, tenantId string) (*model.DmnInfo, error)
+	DmnInfoByKeyVerTenant(key string, ver int, tenantId string) (*model.DmnInfo, error)
+	DmnXmlByKeyTenant(key, tenantId string) (*model.DmnXml, error)
+	DmnXmlByKeyVerTenant(key string, ver int, tenantId string) (*model.DmnXml, error)
+	DmnByKeyTenant(key, tenantId string) (*model.Dmn, error)
+	DmnByKeyVerTenant(key string, ver int, tenantId string) (*model.Dmn, error)
+
+	Evaluate(id string, vars map[string]interface{}) ([]model.ResultEntry, error)
+	EvaluateByKey(key string, vars map[string]interface{}) ([]model.ResultEntry, error)
+	EvaluateByKeyTenant(key, tenantId string, vars map[string]interface{}) ([]model.ResultEntry, error)
+
+	DmnDiagramById(id string) (*model.DmnDiagram, error)
+	DmnDiagramByKey(key string) (*model.DmnDiagram, error)
+	DmnDiagramByKeyTenant(key, tenantId string) (*model.DmnDiagram, error)
+
+	UpdateHistoryTtlById(id string, ttl *int) (error)
+	UpdateHistoryTtlByKey(key string, ttl *int) (error)
+	UpdateHistoryTtlByKeyTenant(key, tenantId string, ttl *int) (error)
+
+	DmnListContext(ctx context.Context) (*model.DmnList, error)
+	DmnListWhereContext(ctx context.Context, q *DmnQuery) (*model.DmnList, error)
+	DmnCountContext(ctx context.Context, q *DmnQuery) (int, error)
+	DmnIterContext(ctx context.Context, q *DmnQuery) (*DmnIterator)
+	DmnMapContext(ctx context.Context) (model.DmnMap, error)
+	DmnInfoByIdContext(ctx context.Context, id string) (*model.DmnInfo, error)
+	DmnInfoByKeyContext(ctx context.Context, key string) (*model.DmnInfo, error)
+	DmnInfoByKeyVerContext(ctx context.Context, key string, ver int) (*model.DmnInfo, error)
+	DmnXmlByIdContext(ctx context.Context, id string) (*model.DmnXml, error)
+	DmnXmlByKeyContext(ctx context.Context, key string) (*model.DmnXml, error)
+	DmnXmlByKeyVerContext(ctx context.Context, key string, ver int) (*model.DmnXml, error)
+	DmnByIdContext(ctx context.Context, id string) (*model.Dmn, error)
+	DmnByKeyContext(ctx context.Context, key string) (*model.Dmn, error)
+	DmnByKeyVerContext(ctx context.Context, key string, ver int) (*model.Dmn, error)
+
+	DmnInfoByKeyTenantContext(ctx context.Context, key, tenantId string) (*model.DmnInfo, error)
+	DmnInfoByKeyVerTenantContext(ctx context.Context, key string, ver int, tenantId string) (*model.DmnInfo, error)
+	DmnXmlByKeyTenantContext(ctx context.Context, key, tenantId string) (*model.DmnXml, error)
+	DmnXmlByKeyVerTenantContext(ctx context.Context, key string, ver int, tenantId string) (*model.DmnXml, error)
+	DmnByKeyTenantContext(ctx context.Context, key, tenantId string) (*model.Dmn, error)
+	DmnByKeyVerTenantContext(ctx context.Context, key string, ver int, tenantId string) (*model.Dmn, error)
+
+	EvaluateContext(ctx context.Context, id string, vars map[string]interface{}) ([]model.ResultEntry, error)
+	EvaluateByKeyContext(ctx context.Context, key string, vars map[string]interface{}) ([]model.ResultEntry, error)
+	EvaluateByKeyTenantContext(ctx context.Context, key, tenantId string, vars map[string]interface{}) ([]model.ResultEntry, error)
+
+	DmnDiagramByIdContext(ctx context.Context, id string) (*model.DmnDiagram, error)
+	DmnDiagramByKeyContext(ctx context.Context, key string) (*model.DmnDiagram, error)
+	DmnDiagramByKeyTenantContext(ctx context.Context, key, tenantId string) (*model.DmnDiagram, error)
+
+	UpdateHistoryTtlByIdContext(ctx context.Context, id string, ttl *int) (error)
+	UpdateHistoryTtlByKeyContext(ctx context.Context, key string, ttl *int) (error)
+	UpdateHistoryTtlByKeyTenantContext(ctx context.Context, key, tenantId string, ttl *int) (error)
 }
 
 type dmnApi struct {
 	Server string
+	listMutex sync.Mutex
 	dmnList *model.DmnList
+	mapMutex sync.Mutex
 	dmnMap model.DmnMap
+	pageSize int
+	creds Credentials
+	client *http.Client
+	tlsConfig *tls.Config
+	proxy *url.URL
+	timeout time.Duration
+	retry *RetryPolicy
+	breaker *CircuitBreaker
 }
 
-func NewDmnApi(server string) (DmnApi) {
-	return &dmnApi{server + epDmnPrefix, nil, nil}
+func NewDmnApi(server string, opts ...Option) (DmnApi) {
+
+	this := &dmnApi{Server: server + epDmnPrefix}
+
+	for _, opt := range opts {
+		opt(this)
+	}
+
+	if this.client == nil {
+		this.client = &http.Client{}
+	}
+
+	if this.tlsConfig != nil || this.proxy != nil {
+
+		transport, ok := this.client.Transport.(*http.Transport)
+
+		if !ok {
+			transport = http.DefaultTransport.(*http.Transport)
+		}
+
+		transport = transport.Clone()
+
+		if this.tlsConfig != nil {
+			transport.TLSClientConfig = this.tlsConfig
+		}
+		if this.proxy != nil {
+			transport.Proxy = http.ProxyURL(this.proxy)
+		}
+
+		client := *this.client
+		client.Transport = transport
+		this.client = &client
+	}
+
+	return this
 }
 
 func (this *dmnApi) DmnList() (*model.DmnList, error) {
+	return this.DmnListContext(context.Background())
+}
+
+func (this *dmnApi) DmnListWhere(q *DmnQuery) (*model.DmnList, error) {
+	return this.DmnListWhereContext(context.Background(), q)
+}
+
+func (this *dmnApi) DmnCount(q *DmnQuery) (int, error) {
+	return this.DmnCountContext(context.Background(), q)
+}
+
+func (this *dmnApi) DmnIter(q *DmnQuery) (*DmnIterator) {
+	return this.DmnIterContext(context.Background(), q)
+}
+
+func (this *dmnApi) DmnMap() (model.DmnMap, error) {
+	return this.DmnMapContext(context.Background())
+}
+
+func (this *dmnApi) DmnInfoById(id string) (*model.DmnInfo, error) {
+	return this.DmnInfoByIdContext(context.Background(), id)
+}
+
+func (this *dmnApi) DmnInfoByKey(key string) (*model.DmnInfo, error) {
+	return this.DmnInfoByKeyContext(context.Background(), key)
+}
+
+func (this *dmnApi) DmnInfoByKeyVer(key string, ver int) (*model.DmnInfo, error) {
+	return this.DmnInfoByKeyVerContext(context.Background(), key, ver)
+}
+
+func (this *dmnApi) DmnXmlById(id string) (*model.DmnXml, error) {
+	return this.DmnXmlByIdContext(context.Background(), id)
+}
+
+func (this *dmnApi) DmnXmlByKey(key string) (*model.DmnXml, error) {
+	return this.DmnXmlByKeyContext(context.Background(), key)
+}
+
+func (this *dmnApi) DmnXmlByKeyVer(key string, ver int) (*model.DmnXml, error) {
+	return this.DmnXmlByKeyVerContext(context.Background(), key, ver)
+}
+
+func (this *dmnApi) DmnById(id string) (*model.Dmn, error) {
+	return this.DmnByIdContext(context.Background(), id)
+}
+
+func (this *dmnApi) DmnByKey(key string) (*model.Dmn, error) {
+	return this.DmnByKeyContext(context.Background(), key)
+}
+
+func (this *dmnApi) DmnByKeyVer(key string, ver int) (*model.Dmn, error) {
+	return this.DmnByKeyVerContext(context.Background(), key, ver)
+}
+
+func (this *dmnApi) DmnInfoByKeyTenant(key, tenantId string) (*model.DmnInfo, error) {
+	return this.DmnInfoByKeyTenantContext(context.Background(), key, tenantId)
+}
+
+func (this *dmnApi) DmnInfoByKeyVerTenant(key string, ver int, tenantId string) (*model.DmnInfo, error) {
+	return this.DmnInfoByKeyVerTenantContext(context.Background(), key, ver, tenantId)
+}
+
+func (this *dmnApi) DmnXmlByKeyTenant(key, tenantId string) (*model.DmnXml, error) {
+	return this.DmnXmlByKeyTenantContext(context.Background(), key, tenantId)
+}
+
+func (this *dmnApi) DmnXmlByKeyVerTenant(key string, ver int, tenantId string) (*model.DmnXml, error) {
+	return this.DmnXmlByKeyVerTenantContext(context.Background(), key, ver, tenantId)
+}
+
+func (this *dmnApi) DmnByKeyTenant(key, tenantId string) (*model.Dmn, error) {
+	return this.DmnByKeyTenantContext(context.Background(), key, tenantId)
+}
+
+func (this *dmnApi) DmnByKeyVerTenant(key string, ver int, tenantId string) (*model.Dmn, error) {
+	return this.DmnByKeyVerTenantContext(context.Background(), key, ver, tenantId)
+}
+
+func (this *dmnApi) DmnListContext(ctx context.Context) (*model.DmnList, error) {
+
+	this.listMutex.Lock()
+	defer this.listMutex.Unlock()
 
 	if this.dmnList != nil {
 		return this.dmnList, nil
 	}
 
-	url := this.Server + epDmnList.String()
-
-	if dl, err := model.NewDmnList(url); err != nil {
+	if dl, err := this.DmnListWhereContext(ctx, nil); err != nil {
 		return nil, err
 	} else {
 		this.dmnList = dl
@@ -121,13 +313,68 @@ func (this *dmnApi) DmnList() (*model.DmnList, error) {
 	}
 }
 
-func (this *dmnApi) DmnMap() (model.DmnMap, error) {
+func (this *dmnApi) DmnListWhereContext(ctx context.Context, q *DmnQuery) (*model.DmnList, error) {
+	return this.DmnIterContext(ctx, q).List()
+}
+
+func (this *dmnApi) DmnCountContext(ctx context.Context, q *DmnQuery) (int, error) {
+
+	url := this.Server + epDmnCount.String()
+
+	if q != nil {
+		url += `?` + q.countValues().Encode()
+	}
+
+	if body, err := this.get(ctx, url); err != nil {
+		return 0, err
+	} else if dc, err := model.NewDmnCount(body); err != nil {
+		return 0, &DecodeError{url, err}
+	} else {
+		return dc.Count, nil
+	}
+}
+
+func (this *dmnApi) DmnIterContext(ctx context.Context, q *DmnQuery) (*DmnIterator) {
+
+	fetch := func(q *DmnQuery) (model.DmnList, error) {
+		return this.fetchList(ctx, q)
+	}
+
+	count := func(q *DmnQuery) (int, error) {
+		return this.DmnCountContext(ctx, q)
+	}
+
+	return newDmnIterator(q, this.pageSize, fetch, count)
+}
+
+// fetchList retrieves one list of definitions matching a query.
+func (this *dmnApi) fetchList(ctx context.Context, q *DmnQuery) (model.DmnList, error) {
+
+	url := this.Server + epDmnList.String()
+
+	if v := q.Values(); len(v) > 0 {
+		url += `?` + v.Encode()
+	}
+
+	if body, err := this.get(ctx, url); err != nil {
+		return nil, err
+	} else if dl, err := model.NewDmnList(body); err != nil {
+		return nil, &DecodeError{url, err}
+	} else {
+		return *dl, nil
+	}
+}
+
+func (this *dmnApi) DmnMapContext(ctx context.Context) (model.DmnMap, error) {
+
+	this.mapMutex.Lock()
+	defer this.mapMutex.Unlock()
 
 	if this.dmnMap != nil {
 		return this.dmnMap, nil
 	}
 
-	if dl, err := this.DmnList(); err != nil {
+	if dl, err := this.DmnListContext(ctx); err != nil {
 		return nil, err
 	} else if dm, err := dl.Map(); err != nil {
 		return nil, err
@@ -137,71 +384,190 @@ func (this *dmnApi) DmnMap() (model.DmnMap, error) {
 	}
 }
 
-func (this *dmnApi) DmnInfoById(id string) (*model.DmnInfo, error) {
+func (this *dmnApi) DmnInfoByIdContext(ctx context.Context, id string) (*model.DmnInfo, error) {
+
 	url := this.Server + epDmnInfoById.With(id)
-	return model.NewDmnInfo(url)
+
+	if body, err := this.get(ctx, url); err != nil {
+		return nil, err
+	} else if di, err := model.NewDmnInfo(body); err != nil {
+		return nil, &DecodeError{url, err}
+	} else {
+		return di, nil
+	}
 }
 
-func (this *dmnApi) DmnInfoByKey(key string) (*model.DmnInfo, error) {
+func (this *dmnApi) DmnInfoByKeyContext(ctx context.Context, key string) (*model.DmnInfo, error) {
+
 	url := this.Server + epDmnInfoByKey.With(key)
-	return model.NewDmnInfo(url)
+
+	if body, err := this.get(ctx, url); err != nil {
+		return nil, err
+	} else if di, err := model.NewDmnInfo(body); err != nil {
+		return nil, &DecodeError{url, err}
+	} else {
+		return di, nil
+	}
 }
 
-func (this *dmnApi) DmnInfoByKeyVer(key string, ver int) (*model.DmnInfo, error) {
+func (this *dmnApi) DmnInfoByKeyVerContext(ctx context.Context, key string, ver int) (*model.DmnInfo, error) {
 
-	if dm, err := this.DmnMap(); err != nil {
+	if dm, err := this.DmnMapContext(ctx); err != nil {
 		return nil, err
 	} else if di, err := dm.DmnInfo(key, ver); err != nil {
-		return nil, err
+		return nil, notFound(this.Server, `%v`, err)
 	} else {
 		return di, nil
 	}
 }
 
-func (this *dmnApi) DmnXmlById(id string) (*model.DmnXml, error) {
+func (this *dmnApi) DmnXmlByIdContext(ctx context.Context, id string) (*model.DmnXml, error) {
+
 	url := this.Server + epDmnXmlById.With(id)
-	return model.NewDmnXml(url)
+
+	if body, err := this.get(ctx, url); err != nil {
+		return nil, err
+	} else if dx, err := model.NewDmnXml(body); err != nil {
+		return nil, &DecodeError{url, err}
+	} else {
+		return dx, nil
+	}
 }
 
-func (this *dmnApi) DmnXmlByKey(key string) (*model.DmnXml, error) {
+func (this *dmnApi) DmnXmlByKeyContext(ctx context.Context, key string) (*model.DmnXml, error) {
+
 	url := this.Server + epDmnXmlByKey.With(key)
-	return model.NewDmnXml(url)
+
+	if body, err := this.get(ctx, url); err != nil {
+		return nil, err
+	} else if dx, err := model.NewDmnXml(body); err != nil {
+		return nil, &DecodeError{url, err}
+	} else {
+		return dx, nil
+	}
 }
 
-func (this *dmnApi) DmnXmlByKeyVer(key string, ver int) (*model.DmnXml, error) {
+func (this *dmnApi) DmnXmlByKeyVerContext(ctx context.Context, key string, ver int) (*model.DmnXml, error) {
 
-	if di, err := this.DmnInfoByKeyVer(key, ver); err != nil {
+	if di, err := this.DmnInfoByKeyVerContext(ctx, key, ver); err != nil {
 		return nil, err
-	} else if dx, err := this.DmnXmlById(di.Id); err != nil {
+	} else if dx, err := this.DmnXmlByIdContext(ctx, di.Id); err != nil {
 		return nil, err
 	} else {
 		return dx, nil
 	}
 }
 
-func (this *dmnApi) DmnById(id string) (*model.Dmn, error) {
+func (this *dmnApi) DmnByIdContext(ctx context.Context, id string) (*model.Dmn, error) {
 
-	if dx, err := this.DmnXmlById(id); err != nil {
+	if dx, err := this.DmnXmlByIdContext(ctx, id); err != nil {
 		return nil, err
+	} else if dmn, err := model.NewDmn(dx.DmnXml); err != nil {
+		return nil, &DecodeError{this.Server + epDmnXmlById.With(dx.Id), err}
 	} else {
-		return model.NewDmn(dx.DmnXml)
+		return dmn, nil
 	}
 }
 
-func (this *dmnApi) DmnByKey(key string) (*model.Dmn, error) {
+func (this *dmnApi) DmnByKeyContext(ctx context.Context, key string) (*model.Dmn, error) {
 
-	if dx, err := this.DmnXmlByKey(key); err != nil {
+	if dx, err := this.DmnXmlByKeyContext(ctx, key); err != nil {
 		return nil, err
+	} else if dmn, err := model.NewDmn(dx.DmnXml); err != nil {
+		return nil, &DecodeError{this.Server + epDmnXmlById.With(dx.Id), err}
 	} else {
-		return model.NewDmn(dx.DmnXml)
+		return dmn, nil
 	}
 }
 
-func (this *dmnApi) DmnByKeyVer(key string, ver int) (*model.Dmn, error) {
+func (this *dmnApi) DmnByKeyVerContext(ctx context.Context, key string, ver int) (*model.Dmn, error) {
+
+	if dx, err := this.DmnXmlByKeyVerContext(ctx, key, ver); err != nil {
+		return nil, err
+	} else if dmn, err := model.NewDmn(dx.DmnXml); err != nil {
+		return nil, &DecodeError{this.Server + epDmnXmlById.With(dx.Id), err}
+	} else {
+		return dmn, nil
+	}
+}
+
+// The tenant variants select definitions of a tenant. An empty tenant id
+// selects definitions that belong to no tenant, like the plain variants.
+
+func (this *dmnApi) DmnInfoByKeyTenantContext(ctx context.Context, key, tenantId string) (*model.DmnInfo, error) {
+
+	if tenantId == `` {
+		return this.DmnInfoByKeyContext(ctx, key)
+	}
+
+	url := this.Server + epDmnInfoByKeyTenant.With(key, tenantId)
+
+	if body, err := this.get(ctx, url); err != nil {
+		return nil, err
+	} else if di, err := model.NewDmnInfo(body); err != nil {
+		return nil, &DecodeError{url, err}
+	} else {
+		return di, nil
+	}
+}
+
+func (this *dmnApi) DmnInfoByKeyVerTenantContext(ctx context.Context, key string, ver int, tenantId string) (*model.DmnInfo, error) {
+
+	if dm, err := this.DmnMapContext(ctx); err != nil {
+		return nil, err
+	} else if di, err := dm.DmnInfoTenant(key, ver, tenantId); err != nil {
+		return nil, notFound(this.Server, `%v`, err)
+	} else {
+		return di, nil
+	}
+}
+
+func (this *dmnApi) DmnXmlByKeyTenantContext(ctx context.Context, key, tenantId string) (*model.DmnXml, error) {
+
+	if tenantId == `` {
+		return this.DmnXmlByKeyContext(ctx, key)
+	}
+
+	url := this.Server + epDmnXmlByKeyTenant.With(key, tenantId)
+
+	if body, err := this.get(ctx, url); err != nil {
+		return nil, err
+	} else if dx, err := model.NewDmnXml(body); err != nil {
+		return nil, &DecodeError{url, err}
+	} else {
+		return dx, nil
+	}
+}
+
+func (this *dmnApi) DmnXmlByKeyVerTenantContext(ctx context.Context, key string, ver int, tenantId string) (*model.DmnXml, error) {
+
+	if di, err := this.DmnInfoByKeyVerTenantContext(ctx, key, ver, tenantId); err != nil {
+		return nil, err
+	} else if dx, err := this.DmnXmlByIdContext(ctx, di.Id); err != nil {
+		return nil, err
+	} else {
+		return dx, nil
+	}
+}
+
+func (this *dmnApi) DmnByKeyTenantContext(ctx context.Context, key, tenantId string) (*model.Dmn, error) {
+
+	if dx, err := this.DmnXmlByKeyTenantContext(ctx, key, tenantId); err != nil {
+		return nil, err
+	} else if dmn, err := model.NewDmn(dx.DmnXml); err != nil {
+		return nil, &DecodeError{this.Server + epDmnXmlById.With(dx.Id), err}
+	} else {
+		return dmn, nil
+	}
+}
+
+func (this *dmnApi) DmnByKeyVerTenantContext(ctx context.Context, key string, ver int, tenantId string) (*model.Dmn, error) {
 
-	if dx, err := this.DmnXmlByKeyVer(key, ver); err != nil {
+	if dx, err := this.DmnXmlByKeyVerTenantContext(ctx, key, ver, tenantId); err != nil {
 		return nil, err
+	} else if dmn, err := model.NewDmn(dx.DmnXml); err != nil {
+		return nil, &DecodeError{this.Server + epDmnXmlById.With(dx.Id), err}
 	} else {
-		return model.NewDmn(dx.DmnXml)
+		return dmn, nil
 	}
 }
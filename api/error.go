@@ -0,0 +1,111 @@
+package api
+
+import (
+	`encoding/json`
+	`errors`
+	`fmt`
+	`net/http`
+)
+
+// ------------------------------------------------------------------------
+// Error.
+// ------------------------------------------------------------------------
+
+// Error is returned for a request the REST API did not complete, or for
+// a definition a snapshot directory does not contain. Type and Message are
+// taken from the Camunda error body, if the response has one.
+type Error struct {
+	StatusCode		int
+	Type			string
+	Message			string
+	Url			string
+}
+
+// Error implements the error interface for Error.
+func (this *Error) Error() (string) {
+
+	msg := fmt.Sprintf(`%s: %d %s`, this.Url, this.StatusCode, http.StatusText(this.StatusCode))
+
+	if this.Type != `` {
+		msg += `: ` + this.Type
+	}
+	if this.Message != `` {
+		msg += `: ` + this.Message
+	}
+
+	return msg
+}
+
+// newError creates an Error from a response with a non-2xx status.
+func newError(url string, resp *response) (*Error) {
+
+	this := &Error{StatusCode: resp.StatusCode, Url: url}
+
+	var body struct {
+		Type			string			`json:"type"`
+		Message			string			`json:"message"`
+	}
+
+	if json.Unmarshal(resp.Body.Bytes(), &body) == nil {
+		this.Type, this.Message = body.Type, body.Message
+	}
+
+	return this
+}
+
+// notFound creates an Error for a definition that does not exist.
+func notFound(url, format string, args ...interface{}) (*Error) {
+	return &Error{StatusCode: http.StatusNotFound, Message: fmt.Sprintf(format, args...), Url: url}
+}
+
+// IsNotFound reports whether an error is an Error for a definition or
+// resource that does not exist.
+func IsNotFound(err error) (bool) {
+	return statusCode(err) == http.StatusNotFound
+}
+
+// IsUnauthorized reports whether an error is an Error for a request that
+// was not authenticated or not authorized.
+func IsUnauthorized(err error) (bool) {
+	code := statusCode(err)
+	return code == http.StatusUnauthorized || code == http.StatusForbidden
+}
+
+// statusCode returns the status code of an Error, or zero.
+func statusCode(err error) (int) {
+
+	var e *Error
+
+	if errors.As(err, &e) {
+		return e.StatusCode
+	}
+
+	return 0
+}
+
+// ------------------------------------------------------------------------
+// DecodeError.
+// ------------------------------------------------------------------------
+
+// DecodeError is returned when a definition was retrieved but its JSON or
+// DMN XML could not be parsed.
+type DecodeError struct {
+	Url			string
+	Err			error
+}
+
+// Error implements the error interface for DecodeError.
+func (this *DecodeError) Error() (string) {
+	return fmt.Sprintf(`%s: %v`, this.Url, this.Err)
+}
+
+// Unwrap returns the underlying parse error.
+func (this *DecodeError) Unwrap() (error) {
+	return this.Err
+}
+
+// IsDecodeError reports whether an error is a DecodeError.
+func IsDecodeError(err error) (bool) {
+	var e *DecodeError
+	return errors.As(err, &e)
+}
@@ -0,0 +1,258 @@
+package api
+
+import (
+	`bytes`
+	`context`
+	`encoding/json`
+	`fmt`
+	`math`
+	`net/http`
+	`strings`
+	`time`
+	`github.com/jscherff/dmnsdk/model`
+)
+
+// Camunda variable types.
+const (
+	TypeNull	= `Null`
+	TypeString	= `String`
+	TypeBoolean	= `Boolean`
+	TypeShort	= `Short`
+	TypeInteger	= `Integer`
+	TypeLong	= `Long`
+	TypeDouble	= `Double`
+	TypeDate	= `Date`
+)
+
+// DateFormat is the default format of Date variables in the REST API.
+const DateFormat = `2006-01-02T15:04:05.000-0700`
+
+// ------------------------------------------------------------------------
+// Variable.
+// ------------------------------------------------------------------------
+
+// Variable is a typed variable in the format of the Camunda REST API.
+type Variable struct {
+	Value			interface{}		`json:"value"`
+	Type			string			`json:"type"`
+	ValueInfo		map[string]interface{}	`json:"valueInfo,omitempty"`
+}
+
+// NewVariable creates a typed variable from a Go value. Strings, booleans,
+// integers, floating point numbers, time.Time and nil are supported; a
+// *Variable is returned as is.
+func NewVariable(val interface{}) (*Variable, error) {
+
+	switch v := val.(type) {
+
+	case *Variable:
+		return v, nil
+	case nil:
+		return &Variable{Type: TypeNull}, nil
+	case string:
+		return &Variable{Value: v, Type: TypeString}, nil
+	case bool:
+		return &Variable{Value: v, Type: TypeBoolean}, nil
+	case int8, int16, int32:
+		return &Variable{Value: v, Type: TypeInteger}, nil
+	case int:
+		if v < math.MinInt32 || v > math.MaxInt32 {
+			return &Variable{Value: v, Type: TypeLong}, nil
+		}
+		return &Variable{Value: v, Type: TypeInteger}, nil
+	case int64:
+		return &Variable{Value: v, Type: TypeLong}, nil
+	case float32, float64:
+		return &Variable{Value: v, Type: TypeDouble}, nil
+	case time.Time:
+		return &Variable{Value: v.Format(DateFormat), Type: TypeDate}, nil
+	}
+
+	return nil, fmt.Errorf(`unsupported variable type %T`, val)
+}
+
+// NewVariables creates typed variables from Go values. See NewVariable.
+func NewVariables(vars map[string]interface{}) (map[string]*Variable, error) {
+
+	tv := make(map[string]*Variable)
+
+	for name, val := range vars {
+		if v, err := NewVariable(val); err != nil {
+			return nil, fmt.Errorf(`variable %s: %v`, name, err)
+		} else {
+			tv[name] = v
+		}
+	}
+
+	return tv, nil
+}
+
+// GoValues converts typed variables to Go values. See GoValue.
+func GoValues(vars map[string]*Variable) (map[string]interface{}, error) {
+
+	gv := make(map[string]interface{})
+
+	for name, v := range vars {
+		if val, err := v.GoValue(); err != nil {
+			return nil, fmt.Errorf(`variable %s: %v`, name, err)
+		} else {
+			gv[name] = val
+		}
+	}
+
+	return gv, nil
+}
+
+// GoValue returns the value of the variable as a Go value of its type:
+// string, bool, int64 for Short, Integer and Long, float64, time.Time or
+// nil. Values of other types are returned as decoded from JSON.
+func (this *Variable) GoValue() (interface{}, error) {
+
+	if this.Value == nil {
+		return nil, nil
+	}
+
+	bad := fmt.Errorf(`invalid %s value %v`, this.Type, this.Value)
+
+	switch strings.ToLower(this.Type) {
+
+	case `string`:
+		if s, ok := this.Value.(string); ok {
+			return s, nil
+		}
+		return nil, bad
+
+	case `boolean`:
+		if b, ok := this.Value.(bool); ok {
+			return b, nil
+		}
+		return nil, bad
+
+	case `short`, `integer`, `long`:
+		switch v := this.Value.(type) {
+		case json.Number:
+			if n, err := v.Int64(); err == nil {
+				return n, nil
+			}
+		case float64:
+			if v == math.Trunc(v) {
+				return int64(v), nil
+			}
+		case int64:
+			return v, nil
+		case int:
+			return int64(v), nil
+		}
+		return nil, bad
+
+	case `double`:
+		switch v := this.Value.(type) {
+		case json.Number:
+			if f, err := v.Float64(); err == nil {
+				return f, nil
+			}
+		case float64:
+			return v, nil
+		}
+		return nil, bad
+
+	case `date`:
+		if s, ok := this.Value.(string); !ok {
+			return nil, bad
+		} else if t, err := time.Parse(DateFormat, s); err == nil {
+			return t, nil
+		} else if t, err := time.Parse(time.RFC3339, s); err == nil {
+			return t, nil
+		}
+		return nil, bad
+	}
+
+	return this.Value, nil
+}
+
+// ------------------------------------------------------------------------
+// Evaluation.
+// ------------------------------------------------------------------------
+
+// evaluateRequest is the body of an evaluate request.
+type evaluateRequest struct {
+	Variables		map[string]*Variable	`json:"variables"`
+}
+
+// evaluate posts variables to an evaluate endpoint and converts the
+// typed result variables to Go values.
+func (this *dmnApi) evaluate(ctx context.Context, url string, vars map[string]interface{}) ([]model.ResultEntry, error) {
+
+	tv, err := NewVariables(vars)
+
+	if err != nil {
+		return nil, err
+	}
+
+	b, err := json.Marshal(&evaluateRequest{tv})
+
+	if err != nil {
+		return nil, err
+	}
+
+	body, err := this.send(ctx, http.MethodPost, url, b)
+
+	if err != nil {
+		return nil, err
+	}
+
+	var results []map[string]*Variable
+
+	dec := json.NewDecoder(bytes.NewReader(body.Bytes()))
+	dec.UseNumber()
+
+	if err := dec.Decode(&results); err != nil {
+		return nil, &DecodeError{url, err}
+	}
+
+	entries := make([]model.ResultEntry, len(results))
+
+	for i, result := range results {
+
+		entries[i] = make(model.ResultEntry)
+
+		for name, v := range result {
+			if val, err := v.GoValue(); err != nil {
+				return nil, &DecodeError{url, fmt.Errorf(`output %s: %v`, name, err)}
+			} else {
+				entries[i][name] = val
+			}
+		}
+	}
+
+	return entries, nil
+}
+
+func (this *dmnApi) Evaluate(id string, vars map[string]interface{}) ([]model.ResultEntry, error) {
+	return this.EvaluateContext(context.Background(), id, vars)
+}
+
+func (this *dmnApi) EvaluateByKey(key string, vars map[string]interface{}) ([]model.ResultEntry, error) {
+	return this.EvaluateByKeyContext(context.Background(), key, vars)
+}
+
+func (this *dmnApi) EvaluateByKeyTenant(key, tenantId string, vars map[string]interface{}) ([]model.ResultEntry, error) {
+	return this.EvaluateByKeyTenantContext(context.Background(), key, tenantId, vars)
+}
+
+func (this *dmnApi) EvaluateContext(ctx context.Context, id string, vars map[string]interface{}) ([]model.ResultEntry, error) {
+	return this.evaluate(ctx, this.Server + epEvaluateById.With(id), vars)
+}
+
+func (this *dmnApi) EvaluateByKeyContext(ctx context.Context, key string, vars map[string]interface{}) ([]model.ResultEntry, error) {
+	return this.evaluate(ctx, this.Server + epEvaluateByKey.With(key), vars)
+}
+
+func (this *dmnApi) EvaluateByKeyTenantContext(ctx context.Context, key, tenantId string, vars map[string]interface{}) ([]model.ResultEntry, error) {
+
+	if tenantId == `` {
+		return this.EvaluateByKeyContext(ctx, key, vars)
+	}
+
+	return this.evaluate(ctx, this.Server + epEvaluateByKeyTenant.With(key, tenantId), vars)
+}
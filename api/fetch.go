@@ -0,0 +1,91 @@
+package api
+
+import (
+	`context`
+	`github.com/jscherff/dmnsdk/model`
+)
+
+// ------------------------------------------------------------------------
+// Fetch Pipeline.
+// ------------------------------------------------------------------------
+
+// FetchFunc retrieves data for one decision definition.
+type FetchFunc func(ctx context.Context, di *model.DmnInfo) (interface{}, error)
+
+// FetchResult is the outcome of a FetchFunc for one definition.
+type FetchResult struct {
+	DmnInfo			*model.DmnInfo
+	Value			interface{}
+	Err			error
+}
+
+// Fetch calls fn for every definition in a list, running up to workers
+// calls concurrently, and delivers the results on the returned channel in
+// list order, so that output built from them is deterministic. At most
+// twice as many results as workers are held waiting for an earlier one.
+// The channel is closed after the last result or once the context is
+// done; a caller that stops reading early must cancel the context.
+func Fetch(ctx context.Context, dl model.DmnList, workers int, fn FetchFunc) (<-chan *FetchResult) {
+
+	if workers < 1 {
+		workers = 1
+	}
+
+	out := make(chan *FetchResult)
+	jobs := make(chan int)
+	window := make(chan struct{}, 2 * workers)
+	slots := make([]chan *FetchResult, len(dl))
+
+	for i := range slots {
+		slots[i] = make(chan *FetchResult, 1)
+	}
+
+	// Feed list positions to the workers, staying within the window of
+	// results not yet delivered.
+
+	go func() {
+		defer close(jobs)
+		for i := range dl {
+			select {
+			case window <- struct{}{}:
+			case <-ctx.Done():
+				return
+			}
+			select {
+			case jobs <- i:
+			case <-ctx.Done():
+				return
+			}
+		}
+	}()
+
+	for w := 0; w < workers; w++ {
+		go func() {
+			for i := range jobs {
+				val, err := fn(ctx, dl[i])
+				slots[i] <- &FetchResult{dl[i], val, err}
+			}
+		}()
+	}
+
+	// Deliver the results in list order.
+
+	go func() {
+		defer close(out)
+		for i := range dl {
+			select {
+			case res := <-slots[i]:
+				select {
+				case out <- res:
+				case <-ctx.Done():
+					return
+				}
+				<-window
+			case <-ctx.Done():
+				return
+			}
+		}
+	}()
+
+	return out
+}
@@ -0,0 +1,167 @@
+package api
+
+import (
+	`context`
+	`fmt`
+	`sync/atomic`
+	`testing`
+	`time`
+	`github.com/jscherff/dmnsdk/model`
+)
+
+// testList creates a list of n definitions with ids d0, d1 and so on.
+func testList(n int) (model.DmnList) {
+
+	dl := make(model.DmnList, n)
+
+	for i := range dl {
+		dl[i] = &model.DmnInfo{Id: fmt.Sprintf(`d%d`, i)}
+	}
+
+	return dl
+}
+
+// testIndex returns the position of a definition created by testList.
+func testIndex(di *model.DmnInfo) (i int) {
+	fmt.Sscanf(di.Id, `d%d`, &i)
+	return i
+}
+
+func TestFetchOrder(t *testing.T) {
+
+	tests := []struct {
+		defs	int
+		workers	int
+	}{
+		{0, 4},
+		{1, 1},
+		{10, 0},
+		{20, 4},
+		{5, 10},
+	}
+
+	for _, tt := range tests {
+
+		var running, peak int32
+
+		// Later definitions finish sooner, so results arrive out of order.
+
+		fn := func(ctx context.Context, di *model.DmnInfo) (interface{}, error) {
+
+			n := atomic.AddInt32(&running, 1)
+			defer atomic.AddInt32(&running, -1)
+
+			for p := atomic.LoadInt32(&peak); n > p && !atomic.CompareAndSwapInt32(&peak, p, n); {
+				p = atomic.LoadInt32(&peak)
+			}
+
+			i := testIndex(di)
+			time.Sleep(time.Duration(tt.defs - i) * time.Millisecond)
+
+			if i % 3 == 1 {
+				return nil, fmt.Errorf(`fail %s`, di.Id)
+			}
+
+			return di.Id, nil
+		}
+
+		dl := testList(tt.defs)
+		i := 0
+
+		for res := range Fetch(context.Background(), dl, tt.workers, fn) {
+
+			if i >= len(dl) || res.DmnInfo != dl[i] {
+				t.Fatalf(`%d/%d: result %d is for %s`, tt.defs, tt.workers, i, res.DmnInfo.Id)
+			} else if i % 3 == 1 && (res.Err == nil || res.Value != nil) {
+				t.Errorf(`%d/%d: result %d = %v, %v; want error`, tt.defs, tt.workers, i, res.Value, res.Err)
+			} else if i % 3 != 1 && (res.Err != nil || res.Value != dl[i].Id) {
+				t.Errorf(`%d/%d: result %d = %v, %v; want %s`, tt.defs, tt.workers, i, res.Value, res.Err, dl[i].Id)
+			}
+
+			i++
+		}
+
+		if i != len(dl) {
+			t.Errorf(`%d/%d: received %d results, want %d`, tt.defs, tt.workers, i, len(dl))
+		}
+
+		max := int32(tt.workers)
+
+		if max < 1 {
+			max = 1
+		}
+
+		if peak > max {
+			t.Errorf(`%d/%d: %d calls ran concurrently`, tt.defs, tt.workers, peak)
+		}
+	}
+}
+
+func TestFetchWindow(t *testing.T) {
+
+	var calls int32
+
+	fn := func(ctx context.Context, di *model.DmnInfo) (interface{}, error) {
+		atomic.AddInt32(&calls, 1)
+		return nil, nil
+	}
+
+	ctx, cancel := context.WithCancel(context.Background())
+	defer cancel()
+
+	// Without a reader, no more than twice as many calls as workers are
+	// made ahead of the first result.
+
+	out := Fetch(ctx, testList(20), 2, fn)
+	time.Sleep(50 * time.Millisecond)
+
+	if n := atomic.LoadInt32(&calls); n != 4 {
+		t.Errorf(`%d calls without a reader, want 4`, n)
+	}
+
+	if res := <-out; res.DmnInfo.Id != `d0` {
+		t.Errorf(`first result is for %s`, res.DmnInfo.Id)
+	}
+}
+
+func TestFetchCancel(t *testing.T) {
+
+	var calls int32
+
+	ctx, cancel := context.WithCancel(context.Background())
+
+	fn := func(ctx context.Context, di *model.DmnInfo) (interface{}, error) {
+		atomic.AddInt32(&calls, 1)
+		if testIndex(di) >= 3 {
+			<-ctx.Done()
+			return nil, ctx.Err()
+		}
+		return di.Id, nil
+	}
+
+	out := Fetch(ctx, testList(100), 2, fn)
+
+	for i := 0; i < 3; i++ {
+		if res := <-out; res == nil || res.Err != nil {
+			t.Fatalf(`result %d = %v before cancellation`, i, res)
+		}
+	}
+
+	cancel()
+
+	done := time.After(time.Second)
+
+	for {
+		select {
+		case _, ok := <-out:
+			if !ok {
+				if n := atomic.LoadInt32(&calls); n > 10 {
+					t.Errorf(`%d calls after cancellation`, n)
+				}
+				return
+			}
+		case <-done:
+			t.Fatalf(`results not closed after cancellation`)
+		}
+	}
+}
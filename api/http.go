@@ -0,0 +1,179 @@
+package api
+
+import (
+	`bytes`
+	`context`
+	`io`
+	`net/http`
+)
+
+// response is an HTTP response with its body read.
+type response struct {
+	StatusCode		int
+	Header			http.Header
+	Body			*bytes.Buffer
+}
+
+// get retrieves a resource from the REST API and returns the response
+// body.
+func (this *dmnApi) get(ctx context.Context, url string) (*bytes.Buffer, error) {
+	return this.send(ctx, http.MethodGet, url, nil)
+}
+
+// send sends a request with an optional JSON body to the REST API and
+// returns the response body.
+func (this *dmnApi) send(ctx context.Context, method, url string, body []byte) (*bytes.Buffer, error) {
+
+	if resp, err := this.exchange(ctx, method, url, `application/json`, body); err != nil {
+		return nil, err
+	} else {
+		return resp.Body, nil
+	}
+}
+
+// exchange sends a request that accepts the given media type and returns
+// the response. Transient failures of idempotent requests are retried
+// under the retry policy of the client, if any. Other requests, such as
+// a POST to evaluate a decision, are sent once, since a failed attempt may
+// still have been processed. Every attempt is checked against and
+// recorded by the circuit breaker of the client, if any.
+func (this *dmnApi) exchange(ctx context.Context, method, url, accept string, body []byte) (*response, error) {
+
+	retry := this.retry
+
+	if !idempotent(method) {
+		retry = nil
+	}
+
+	for attempt := 1; ; attempt++ {
+
+		if this.breaker != nil {
+			if err := this.breaker.Allow(); err != nil {
+				return nil, err
+			}
+		}
+
+		resp, transient, err := this.attempt(ctx, method, url, accept, body)
+
+		if ctx.Err() != nil {
+			return nil, ctx.Err()
+		}
+
+		if this.breaker != nil {
+			this.breaker.Record(!transient)
+		}
+
+		if !transient || retry == nil || attempt >= retry.MaxAttempts {
+
+			if err != nil {
+				return nil, err
+			} else if resp.StatusCode < 200 || resp.StatusCode > 299 {
+				return nil, newError(url, resp)
+			}
+
+			return resp, nil
+		}
+
+		var header http.Header
+
+		if resp != nil {
+			header = resp.Header
+		}
+
+		if err := sleep(ctx, retry.Delay(attempt, header)); err != nil {
+			return nil, err
+		}
+	}
+}
+
+// idempotent reports whether requests with the given method may safely be
+// sent more than once.
+func idempotent(method string) (bool) {
+
+	switch method {
+	case http.MethodGet, http.MethodHead, http.MethodOptions, http.MethodPut, http.MethodDelete:
+		return true
+	default:
+		return false
+	}
+}
+
+// attempt sends one request, bounded by the client timeout, if any, and
+// reads the response. It reports whether the failure, if any, is
+// transient.
+func (this *dmnApi) attempt(ctx context.Context, method, url, accept string, body []byte) (*response, bool, error) {
+
+	if this.timeout > 0 {
+		var cancel context.CancelFunc
+		ctx, cancel = context.WithTimeout(ctx, this.timeout)
+		defer cancel()
+	}
+
+	req, err := this.request(ctx, method, url, accept, body)
+
+	if err != nil {
+		return nil, false, err
+	}
+
+	resp, err := this.client.Do(req)
+
+	if err != nil {
+		return nil, true, err
+	}
+
+	// Refresh the credentials and retry once if they were rejected.
+
+	if resp.StatusCode == http.StatusUnauthorized {
+		if r, ok := this.creds.(Refresher); ok {
+			resp.Body.Close()
+			if err := r.Refresh(); err != nil {
+				return nil, false, err
+			} else if req, err = this.request(ctx, method, url, accept, body); err != nil {
+				return nil, false, err
+			} else if resp, err = this.client.Do(req); err != nil {
+				return nil, true, err
+			}
+		}
+	}
+
+	defer resp.Body.Close()
+
+	buf := new(bytes.Buffer)
+
+	if _, err := io.Copy(buf, resp.Body); err != nil {
+		return nil, true, err
+	}
+
+	return &response{resp.StatusCode, resp.Header, buf}, transientStatus(resp.StatusCode), nil
+}
+
+// request creates an authenticated request that accepts the given media
+// type.
+func (this *dmnApi) request(ctx context.Context, method, url, accept string, body []byte) (*http.Request, error) {
+
+	var rdr io.Reader
+
+	if body != nil {
+		rdr = bytes.NewReader(body)
+	}
+
+	req, err := http.NewRequestWithContext(ctx, method, url, rdr)
+
+	if err != nil {
+		return nil, err
+	}
+
+	req.Header.Set(`Accept`, accept)
+
+	if body != nil {
+		req.Header.Set(`Content-Type`, `application/json`)
+	}
+
+	if this.creds != nil {
+		if err := this.creds.Apply(req); err != nil {
+			return nil, err
+		}
+	}
+
+	return req, nil
+}
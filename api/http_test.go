@@ -0,0 +1,176 @@
+package api
+
+import (
+	`context`
+	`net/http`
+	`net/http/httptest`
+	`sync/atomic`
+	`testing`
+	`time`
+)
+
+// testRetryPolicy retries quickly so that tests do not wait.
+func testRetryPolicy() (*RetryPolicy) {
+	return &RetryPolicy{MaxAttempts: 3, BaseDelay: time.Millisecond, MaxDelay: time.Millisecond}
+}
+
+func TestEvaluateNotRetried(t *testing.T) {
+
+	var calls int32
+
+	ts := httptest.NewServer(http.HandlerFunc(func(w http.ResponseWriter, r *http.Request) {
+		atomic.AddInt32(&calls, 1)
+		w.WriteHeader(http.StatusServiceUnavailable)
+	}))
+
+	defer ts.Close()
+
+	cb := NewCircuitBreaker(1, time.Hour)
+	dmnApi := NewDmnApi(ts.URL, WithRetry(testRetryPolicy()), WithCircuitBreaker(cb))
+
+	if _, err := dmnApi.Evaluate(`d:1:1`, map[string]interface{}{`x`: 1}); err == nil {
+		t.Fatalf(`Evaluate succeeded, want error`)
+	} else if n := atomic.LoadInt32(&calls); n != 1 {
+		t.Errorf(`Evaluate sent %d requests, want 1`, n)
+	}
+
+	if _, err := dmnApi.Evaluate(`d:1:1`, nil); err != ErrCircuitOpen {
+		t.Errorf(`Evaluate after failure = %v, want %v`, err, ErrCircuitOpen)
+	} else if n := atomic.LoadInt32(&calls); n != 1 {
+		t.Errorf(`open circuit sent %d requests, want 1`, n)
+	}
+}
+
+// testServer answers DmnCount requests with the given statuses in turn,
+// repeating the last one, and counts the requests it receives.
+func testServer(statuses ...int) (*httptest.Server, *int32) {
+
+	var calls int32
+
+	ts := httptest.NewServer(http.HandlerFunc(func(w http.ResponseWriter, r *http.Request) {
+
+		n := int(atomic.AddInt32(&calls, 1))
+
+		if n > len(statuses) {
+			n = len(statuses)
+		}
+
+		if code := statuses[n-1]; code != http.StatusOK {
+			w.Header().Set(`Retry-After`, `0`)
+			w.WriteHeader(code)
+		} else {
+			w.Write([]byte(`{"count": 3}`))
+		}
+	}))
+
+	return ts, &calls
+}
+
+func TestRetry(t *testing.T) {
+
+	tests := []struct {
+		name		string
+		statuses	[]int
+		retry		bool
+		calls		int32
+		ok		bool
+	}{
+		{`success`,		[]int{200},		true,	1,	true},
+		{`no policy`,		[]int{503, 200},	false,	1,	false},
+		{`transient`,		[]int{503, 502, 200},	true,	3,	true},
+		{`too many requests`,	[]int{429, 200},	true,	2,	true},
+		{`exhausted`,		[]int{500},		true,	3,	false},
+		{`not transient`,	[]int{404, 200},	true,	1,	false},
+	}
+
+	for _, tt := range tests {
+
+		ts, calls := testServer(tt.statuses...)
+
+		var opts []Option
+
+		if tt.retry {
+			opts = append(opts, WithRetry(testRetryPolicy()))
+		}
+
+		n, err := NewDmnApi(ts.URL, opts...).DmnCountContext(context.Background(), nil)
+		ts.Close()
+
+		if (err == nil) != tt.ok || (tt.ok && n != 3) {
+			t.Errorf(`%s: DmnCount = %d, %v; want success %v`, tt.name, n, err, tt.ok)
+		}
+
+		if got := atomic.LoadInt32(calls); got != tt.calls {
+			t.Errorf(`%s: sent %d requests, want %d`, tt.name, got, tt.calls)
+		}
+	}
+}
+
+func TestRetryCancel(t *testing.T) {
+
+	var calls int32
+
+	ts := httptest.NewServer(http.HandlerFunc(func(w http.ResponseWriter, r *http.Request) {
+		atomic.AddInt32(&calls, 1)
+		w.WriteHeader(http.StatusServiceUnavailable)
+	}))
+
+	defer ts.Close()
+
+	policy := &RetryPolicy{MaxAttempts: 5, BaseDelay: time.Hour, MaxDelay: time.Hour}
+	dmnApi := NewDmnApi(ts.URL, WithRetry(policy))
+
+	ctx, cancel := context.WithTimeout(context.Background(), 50 * time.Millisecond)
+	defer cancel()
+
+	if _, err := dmnApi.DmnCountContext(ctx, nil); err != context.DeadlineExceeded {
+		t.Errorf(`DmnCount = %v, want %v`, err, context.DeadlineExceeded)
+	} else if got := atomic.LoadInt32(&calls); got != 1 {
+		t.Errorf(`sent %d requests, want 1`, got)
+	}
+}
+
+func TestPutRetried(t *testing.T) {
+
+	ts, calls := testServer(503, 204)
+	defer ts.Close()
+
+	dmnApi := NewDmnApi(ts.URL, WithRetry(testRetryPolicy()))
+	ttl := 30
+
+	if err := dmnApi.UpdateHistoryTtlById(`d:1:1`, &ttl); err != nil {
+		t.Errorf(`UpdateHistoryTtlById: %v`, err)
+	} else if got := atomic.LoadInt32(calls); got != 2 {
+		t.Errorf(`sent %d requests, want 2`, got)
+	}
+}
+
+func TestCircuitBreakerClient(t *testing.T) {
+
+	ts, calls := testServer(503, 503, 200)
+	defer ts.Close()
+
+	cb := NewCircuitBreaker(2, 20 * time.Millisecond)
+	dmnApi := NewDmnApi(ts.URL, WithCircuitBreaker(cb))
+	ctx := context.Background()
+
+	for i := 0; i < 2; i++ {
+		if _, err := dmnApi.DmnCountContext(ctx, nil); err == nil || err == ErrCircuitOpen {
+			t.Fatalf(`request %d = %v, want server error`, i+1, err)
+		}
+	}
+
+	if _, err := dmnApi.DmnCountContext(ctx, nil); err != ErrCircuitOpen {
+		t.Fatalf(`request with open circuit = %v, want %v`, err, ErrCircuitOpen)
+	} else if got := atomic.LoadInt32(calls); got != 2 {
+		t.Fatalf(`open circuit sent request %d`, got)
+	}
+
+	time.Sleep(30 * time.Millisecond)
+
+	if n, err := dmnApi.DmnCountContext(ctx, nil); err != nil || n != 3 {
+		t.Errorf(`trial request = %d, %v; want 3, nil`, n, err)
+	} else if _, err := dmnApi.DmnCountContext(ctx, nil); err != nil {
+		t.Errorf(`request with closed circuit = %v`, err)
+	}
+}
@@ -0,0 +1,189 @@
+package api
+
+import (
+	`fmt`
+	`github.com/jscherff/dmnsdk/model`
+)
+
+// ------------------------------------------------------------------------
+// DmnIterator.
+// ------------------------------------------------------------------------
+
+// DmnIterator streams the decision definitions matching a query, fetching
+// them one page at a time. When paging, the number of definitions fetched
+// is checked against the count endpoint, so that definitions deployed or
+// deleted while paging are reported as an error rather than silently
+// skipped or repeated. Typical use:
+//
+//	it := dmnApi.DmnIter(query)
+//	for it.Next() {
+//		di := it.DmnInfo()
+//		...
+//	}
+//	if err := it.Err(); err != nil {
+//		...
+//	}
+type DmnIterator struct {
+	query			DmnQuery
+	pageSize		int
+	fetch			func(*DmnQuery) (model.DmnList, error)
+	count			func(*DmnQuery) (int, error)
+	page			model.DmnList
+	dmnInfo			*model.DmnInfo
+	fetched			int
+	expected		int
+	done			bool
+	err			error
+}
+
+// newDmnIterator creates a DmnIterator that fetches pages of pageSize
+// definitions with fetch and checks the total with count. A page size of
+// zero fetches all definitions in one request.
+func newDmnIterator(q *DmnQuery, pageSize int,
+	fetch func(*DmnQuery) (model.DmnList, error),
+	count func(*DmnQuery) (int, error)) (*DmnIterator) {
+
+	this := &DmnIterator{pageSize: pageSize, fetch: fetch, count: count, expected: -1}
+
+	if q != nil {
+		this.query = *q
+	}
+
+	// Paging is only reliable over a stable order.
+
+	if pageSize > 0 && this.query.SortBy == `` {
+		this.query.SortBy, this.query.SortOrder = `id`, SortAsc
+	}
+
+	return this
+}
+
+// Next advances to the next definition and reports whether there is one.
+func (this *DmnIterator) Next() (bool) {
+
+	this.dmnInfo = nil
+
+	for len(this.page) == 0 {
+		if this.done || this.err != nil {
+			return false
+		}
+		this.err = this.nextPage()
+	}
+
+	this.dmnInfo, this.page = this.page[0], this.page[1:]
+	return true
+}
+
+// DmnInfo returns the current definition.
+func (this *DmnIterator) DmnInfo() (*model.DmnInfo) {
+	return this.dmnInfo
+}
+
+// Err returns the error, if any, that ended the iteration.
+func (this *DmnIterator) Err() (error) {
+	return this.err
+}
+
+// List collects the remaining definitions into a DmnList.
+func (this *DmnIterator) List() (*model.DmnList, error) {
+
+	dl := make(model.DmnList, 0)
+
+	for this.Next() {
+		dl = append(dl, this.DmnInfo())
+	}
+
+	if this.err != nil {
+		return nil, this.err
+	}
+
+	return &dl, nil
+}
+
+// nextPage fetches the next page of definitions.
+func (this *DmnIterator) nextPage() (error) {
+
+	if err := this.query.Validate(); err != nil {
+		return err
+	}
+
+	if this.pageSize == 0 {
+		this.done = true
+		page, err := this.fetch(&this.query)
+		this.page = page
+		return err
+	}
+
+	if this.expected < 0 {
+		if err := this.total(); err != nil {
+			return err
+		}
+	}
+
+	size := this.pageSize
+
+	if this.query.MaxResults > 0 {
+		if left := this.query.MaxResults - this.fetched; left <= 0 {
+			this.done = true
+			return this.check()
+		} else if left < size {
+			size = left
+		}
+	}
+
+	q := this.query
+	q.FirstResult += this.fetched
+	q.MaxResults = size
+
+	page, err := this.fetch(&q)
+
+	if err != nil {
+		return err
+	}
+
+	this.page = page
+	this.fetched += len(page)
+
+	if len(page) < size {
+		this.done = true
+		return this.check()
+	}
+
+	return nil
+}
+
+// total determines the number of definitions the iteration should yield.
+func (this *DmnIterator) total() (error) {
+
+	if this.count == nil {
+		return nil
+	}
+
+	n, err := this.count(&this.query)
+
+	if err != nil {
+		return err
+	}
+
+	if n -= this.query.FirstResult; n < 0 {
+		n = 0
+	}
+
+	if this.query.MaxResults > 0 && this.query.MaxResults < n {
+		n = this.query.MaxResults
+	}
+
+	this.expected = n
+	return nil
+}
+
+// check compares the number of definitions fetched with the total.
+func (this *DmnIterator) check() (error) {
+
+	if this.expected >= 0 && this.fetched != this.expected {
+		return fmt.Errorf(`decision definitions changed while paging: fetched %d, expected %d`,
+			this.fetched, this.expected)
+	}
+
+	return nil
+}
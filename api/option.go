@@ -0,0 +1,98 @@
+package api
+
+import (
+	`crypto/tls`
+	`crypto/x509`
+	`fmt`
+	`io/ioutil`
+	`net/http`
+	`net/url`
+	`time`
+)
+
+// Option configures a DmnApi created by NewDmnApi.
+type Option func(*dmnApi)
+
+// WithPageSize makes the client fetch decision definition lists in pages
+// of at most n definitions, using the firstResult and maxResults query
+// parameters. Zero, the default, fetches each list in one request.
+func WithPageSize(n int) (Option) {
+	return func(this *dmnApi) {
+		if n > 0 {
+			this.pageSize = n
+		}
+	}
+}
+
+// WithHTTPClient makes the client send requests with an http.Client
+// instead of a default one. WithTLSConfig and WithProxy are applied to a
+// copy of its transport.
+func WithHTTPClient(client *http.Client) (Option) {
+	return func(this *dmnApi) {
+		this.client = client
+	}
+}
+
+// WithTLSConfig sets the TLS configuration of the client transport, for
+// example a custom CA bundle or a client certificate. See TLSConfig.
+func WithTLSConfig(cfg *tls.Config) (Option) {
+	return func(this *dmnApi) {
+		this.tlsConfig = cfg
+	}
+}
+
+// WithProxy sends requests through a proxy instead of the one selected by
+// the HTTP_PROXY, HTTPS_PROXY and NO_PROXY environment variables.
+func WithProxy(proxy *url.URL) (Option) {
+	return func(this *dmnApi) {
+		this.proxy = proxy
+	}
+}
+
+// WithTimeout limits the time of each request, including reading the
+// response body. Zero, the default, means no limit.
+func WithTimeout(d time.Duration) (Option) {
+	return func(this *dmnApi) {
+		this.timeout = d
+	}
+}
+
+// TLSConfig creates a TLS configuration that trusts the CA certificates in
+// caFile in addition to the system roots and, for mutual TLS, presents the
+// client certificate and key in certFile and keyFile. Empty file names
+// are ignored, but a certificate requires a key and vice versa.
+func TLSConfig(caFile, certFile, keyFile string) (*tls.Config, error) {
+
+	cfg := &tls.Config{}
+
+	if caFile != `` {
+
+		pool, err := x509.SystemCertPool()
+
+		if err != nil {
+			pool = x509.NewCertPool()
+		}
+
+		if b, err := ioutil.ReadFile(caFile); err != nil {
+			return nil, err
+		} else if !pool.AppendCertsFromPEM(b) {
+			return nil, fmt.Errorf(`no certificates found in %s`, caFile)
+		}
+
+		cfg.RootCAs = pool
+	}
+
+	if (certFile == ``) != (keyFile == ``) {
+		return nil, fmt.Errorf(`client certificate and key must be used together`)
+	}
+
+	if certFile != `` {
+		if cert, err := tls.LoadX509KeyPair(certFile, keyFile); err != nil {
+			return nil, err
+		} else {
+			cfg.Certificates = []tls.Certificate{cert}
+		}
+	}
+
+	return cfg, nil
+}
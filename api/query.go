@@ -0,0 +1,344 @@
+package api
+
+import (
+	`fmt`
+	`net/url`
+	`regexp`
+	`sort`
+	`strconv`
+	`strings`
+	`github.com/jscherff/dmnsdk/model`
+)
+
+// Sort orders accepted by the sortOrder parameter.
+const (
+	SortAsc		= `asc`
+	SortDesc	= `desc`
+)
+
+// sortKeys maps the values accepted by the sortBy parameter to the
+// DmnInfo field they sort by.
+var sortKeys = map[string]func(*model.DmnInfo) interface{} {
+	`category`:	func(di *model.DmnInfo) interface{} { return di.Category },
+	`key`:		func(di *model.DmnInfo) interface{} { return di.Key },
+	`id`:		func(di *model.DmnInfo) interface{} { return di.Id },
+	`name`:		func(di *model.DmnInfo) interface{} { return di.Name },
+	`version`:	func(di *model.DmnInfo) interface{} { return di.Version },
+	`deploymentId`:	func(di *model.DmnInfo) interface{} { return di.DeploymentId },
+	`tenantId`:	func(di *model.DmnInfo) interface{} { return di.TenantId },
+	`versionTag`:	func(di *model.DmnInfo) interface{} { return di.VersionTag },
+}
+
+// ------------------------------------------------------------------------
+// DmnQuery.
+// ------------------------------------------------------------------------
+
+// DmnQuery holds the parameters of the decision definition list and count
+// endpoints. Zero values are omitted. The Like parameters use SQL LIKE
+// patterns, where % matches any sequence of characters and _ matches any
+// single character. MaxResults of zero means no limit. Sort and paging
+// parameters apply to the list endpoint only.
+type DmnQuery struct {
+	DmnId			string
+	DmnIdIn			[]string
+	Name			string
+	NameLike		string
+	DeploymentId		string
+	Key			string
+	KeyLike			string
+	Category		string
+	CategoryLike		string
+	Version			int
+	LatestVersion		bool
+	ResourceName		string
+	ResourceNameLike	string
+	VersionTag		string
+	TenantIdIn		[]string
+	WithoutTenantId		bool
+	SortBy			string
+	SortOrder		string
+	FirstResult		int
+	MaxResults		int
+}
+
+// ParseDmnQuery creates a DmnQuery from URL query parameters, as received
+// by the decision definition list and count endpoints.
+func ParseDmnQuery(v url.Values) (*DmnQuery, error) {
+
+	this := &DmnQuery{
+		DmnId: v.Get(`decisionDefinitionId`),
+		DmnIdIn: splitList(v.Get(`decisionDefinitionIdIn`)),
+		Name: v.Get(`name`),
+		NameLike: v.Get(`nameLike`),
+		DeploymentId: v.Get(`deploymentId`),
+		Key: v.Get(`key`),
+		KeyLike: v.Get(`keyLike`),
+		Category: v.Get(`category`),
+		CategoryLike: v.Get(`categoryLike`),
+		ResourceName: v.Get(`resourceName`),
+		ResourceNameLike: v.Get(`resourceNameLike`),
+		VersionTag: v.Get(`versionTag`),
+		TenantIdIn: splitList(v.Get(`tenantIdIn`)),
+		SortBy: v.Get(`sortBy`),
+		SortOrder: v.Get(`sortOrder`),
+	}
+
+	ints := map[string]*int{
+		`version`: &this.Version,
+		`firstResult`: &this.FirstResult,
+		`maxResults`: &this.MaxResults,
+	}
+
+	for name, dst := range ints {
+		if s := v.Get(name); s == `` {
+			continue
+		} else if n, err := strconv.Atoi(s); err != nil || n < 0 {
+			return nil, fmt.Errorf(`invalid %s: %q`, name, s)
+		} else {
+			*dst = n
+		}
+	}
+
+	bools := map[string]*bool{
+		`latestVersion`: &this.LatestVersion,
+		`withoutTenantId`: &this.WithoutTenantId,
+	}
+
+	for name, dst := range bools {
+		if s := v.Get(name); s == `` {
+			continue
+		} else if b, err := strconv.ParseBool(s); err != nil {
+			return nil, fmt.Errorf(`invalid %s: %q`, name, s)
+		} else {
+			*dst = b
+		}
+	}
+
+	return this, this.Validate()
+}
+
+// Validate checks the sort parameters, which must be used together.
+func (this *DmnQuery) Validate() (error) {
+
+	if (this.SortBy == ``) != (this.SortOrder == ``) {
+		return fmt.Errorf(`sortBy and sortOrder must be used together`)
+	}
+
+	if this.SortBy != `` && sortKeys[this.SortBy] == nil {
+		return fmt.Errorf(`invalid sortBy: %q`, this.SortBy)
+	}
+
+	if this.SortOrder != `` && this.SortOrder != SortAsc && this.SortOrder != SortDesc {
+		return fmt.Errorf(`invalid sortOrder: %q`, this.SortOrder)
+	}
+
+	return nil
+}
+
+// Values returns the query as URL query parameters.
+func (this *DmnQuery) Values() (url.Values) {
+
+	v := make(url.Values)
+
+	set := func(name, val string) {
+		if val != `` {
+			v.Set(name, val)
+		}
+	}
+
+	set(`decisionDefinitionId`, this.DmnId)
+	set(`decisionDefinitionIdIn`, strings.Join(this.DmnIdIn, `,`))
+	set(`name`, this.Name)
+	set(`nameLike`, this.NameLike)
+	set(`deploymentId`, this.DeploymentId)
+	set(`key`, this.Key)
+	set(`keyLike`, this.KeyLike)
+	set(`category`, this.Category)
+	set(`categoryLike`, this.CategoryLike)
+	set(`resourceName`, this.ResourceName)
+	set(`resourceNameLike`, this.ResourceNameLike)
+	set(`versionTag`, this.VersionTag)
+	set(`tenantIdIn`, strings.Join(this.TenantIdIn, `,`))
+	set(`sortBy`, this.SortBy)
+	set(`sortOrder`, this.SortOrder)
+
+	if this.Version > 0 {
+		v.Set(`version`, strconv.Itoa(this.Version))
+	}
+	if this.LatestVersion {
+		v.Set(`latestVersion`, `true`)
+	}
+	if this.WithoutTenantId {
+		v.Set(`withoutTenantId`, `true`)
+	}
+	if this.FirstResult > 0 {
+		v.Set(`firstResult`, strconv.Itoa(this.FirstResult))
+	}
+	if this.MaxResults > 0 {
+		v.Set(`maxResults`, strconv.Itoa(this.MaxResults))
+	}
+
+	return v
+}
+
+// Encode returns the query as a URL query string, without the leading '?'.
+func (this *DmnQuery) Encode() (string) {
+	return this.Values().Encode()
+}
+
+// countValues returns the query parameters accepted by the count endpoint.
+func (this *DmnQuery) countValues() (url.Values) {
+
+	v := this.Values()
+
+	for _, name := range []string{`sortBy`, `sortOrder`, `firstResult`, `maxResults`} {
+		v.Del(name)
+	}
+
+	return v
+}
+
+// Match reports whether a decision definition satisfies the filter
+// parameters of the query. LatestVersion is not considered, since it
+// depends on the other definitions; see Filter.
+func (this *DmnQuery) Match(di *model.DmnInfo) (bool) {
+
+	eq := func(want, got string) bool { return want == `` || want == got }
+	like := func(pat, got string) bool { return pat == `` || likeRegexp(pat).MatchString(got) }
+	in := func(list []string, got string) bool {
+		if len(list) == 0 {
+			return true
+		}
+		for _, s := range list {
+			if s == got {
+				return true
+			}
+		}
+		return false
+	}
+
+	return eq(this.DmnId, di.Id) && in(this.DmnIdIn, di.Id) &&
+		eq(this.Name, di.Name) && like(this.NameLike, di.Name) &&
+		eq(this.DeploymentId, di.DeploymentId) &&
+		eq(this.Key, di.Key) && like(this.KeyLike, di.Key) &&
+		eq(this.Category, di.Category) && like(this.CategoryLike, di.Category) &&
+		(this.Version == 0 || this.Version == di.Version) &&
+		eq(this.ResourceName, di.Resource) && like(this.ResourceNameLike, di.Resource) &&
+		eq(this.VersionTag, di.VersionTag) && in(this.TenantIdIn, di.TenantId) &&
+		(!this.WithoutTenantId || di.TenantId == ``)
+}
+
+// Count returns the number of definitions in a list that satisfy the
+// filter parameters of the query.
+func (this *DmnQuery) Count(dl model.DmnList) (int) {
+	return len(this.filter(dl))
+}
+
+// Filter applies the query to a list of decision definitions the way the
+// engine does: it selects the matching definitions, then sorts and pages
+// them. The list itself is not modified.
+func (this *DmnQuery) Filter(dl model.DmnList) (model.DmnList) {
+
+	result := this.filter(dl)
+
+	if key := sortKeys[this.SortBy]; key != nil {
+		desc := this.SortOrder == SortDesc
+		sort.SliceStable(result, func(i, j int) bool {
+			if desc {
+				return less(key(result[j]), key(result[i]))
+			}
+			return less(key(result[i]), key(result[j]))
+		})
+	}
+
+	if this.FirstResult >= len(result) {
+		return model.DmnList{}
+	}
+
+	result = result[this.FirstResult:]
+
+	if this.MaxResults > 0 && this.MaxResults < len(result) {
+		result = result[:this.MaxResults]
+	}
+
+	return result
+}
+
+// filter selects the definitions that satisfy the filter parameters. For
+// LatestVersion, the latest version of each key is determined per tenant
+// over the whole list.
+func (this *DmnQuery) filter(dl model.DmnList) (model.DmnList) {
+
+	latest := make(map[string]int)
+
+	if this.LatestVersion {
+		for _, di := range dl {
+			if k := di.TenantId + "\x00" + di.Key; di.Version > latest[k] {
+				latest[k] = di.Version
+			}
+		}
+	}
+
+	result := make(model.DmnList, 0)
+
+	for _, di := range dl {
+		if this.LatestVersion && di.Version != latest[di.TenantId + "\x00" + di.Key] {
+			continue
+		}
+		if this.Match(di) {
+			result = append(result, di)
+		}
+	}
+
+	return result
+}
+
+// ------------------------------------------------------------------------
+// Query Helpers.
+// ------------------------------------------------------------------------
+
+// likeRegexp converts a SQL LIKE pattern to an anchored regular expression.
+func likeRegexp(pat string) (*regexp.Regexp) {
+
+	var sb strings.Builder
+
+	sb.WriteString(`^`)
+
+	for _, r := range pat {
+		switch r {
+		case '%':
+			sb.WriteString(`.*`)
+		case '_':
+			sb.WriteString(`.`)
+		default:
+			sb.WriteString(regexp.QuoteMeta(string(r)))
+		}
+	}
+
+	sb.WriteString(`$`)
+
+	return regexp.MustCompile(sb.String())
+}
+
+// less compares two sort key values of the same type.
+func less(a, b interface{}) (bool) {
+
+	switch a := a.(type) {
+	case int:
+		return a < b.(int)
+	case string:
+		return a < b.(string)
+	}
+
+	return false
+}
+
+// splitList splits a comma-separated list parameter.
+func splitList(s string) ([]string) {
+
+	if s == `` {
+		return nil
+	}
+
+	return strings.Split(s, `,`)
+}
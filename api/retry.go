@@ -0,0 +1,192 @@
+package api
+
+import (
+	`context`
+	`errors`
+	`math/rand`
+	`net/http`
+	`strconv`
+	`sync`
+	`time`
+)
+
+// ------------------------------------------------------------------------
+// RetryPolicy.
+// ------------------------------------------------------------------------
+
+// RetryPolicy controls how requests that fail transiently are retried.
+// Connection errors, timeouts, 429 Too Many Requests and 5xx responses are
+// transient. The delay before retry n is BaseDelay * 2^(n-1), capped at
+// MaxDelay and reduced by a random fraction of up to Jitter so that
+// clients do not retry in lockstep. A Retry-After header overrides the
+// computed delay, still capped at MaxDelay.
+type RetryPolicy struct {
+	MaxAttempts		int
+	BaseDelay		time.Duration
+	MaxDelay		time.Duration
+	Jitter			float64
+}
+
+// DefaultRetryPolicy returns a policy of four attempts, starting with a
+// half second delay.
+func DefaultRetryPolicy() (*RetryPolicy) {
+	return &RetryPolicy{
+		MaxAttempts: 4,
+		BaseDelay: 500 * time.Millisecond,
+		MaxDelay: 30 * time.Second,
+		Jitter: 0.5,
+	}
+}
+
+// WithRetry makes the client retry transient failures of idempotent
+// requests under a policy. Decision evaluations are never retried.
+func WithRetry(policy *RetryPolicy) (Option) {
+	return func(this *dmnApi) {
+		this.retry = policy
+	}
+}
+
+// Delay returns the delay before retrying after the given attempt, which
+// is numbered from one, honoring the Retry-After header of the response
+// to that attempt, if any.
+func (this *RetryPolicy) Delay(attempt int, header http.Header) (time.Duration) {
+
+	delay := this.BaseDelay
+
+	for i := 1; i < attempt && delay < this.MaxDelay; i++ {
+		delay *= 2
+	}
+
+	if this.Jitter > 0 && delay > 0 {
+		delay -= time.Duration(rand.Float64() * this.Jitter * float64(delay))
+	}
+
+	if after, ok := retryAfter(header); ok {
+		delay = after
+	}
+
+	if this.MaxDelay > 0 && delay > this.MaxDelay {
+		delay = this.MaxDelay
+	}
+
+	return delay
+}
+
+// retryAfter parses a Retry-After header in seconds or as an HTTP date.
+func retryAfter(header http.Header) (time.Duration, bool) {
+
+	s := header.Get(`Retry-After`)
+
+	if s == `` {
+		return 0, false
+	} else if n, err := strconv.Atoi(s); err == nil && n >= 0 {
+		return time.Duration(n) * time.Second, true
+	} else if t, err := http.ParseTime(s); err == nil {
+		if d := time.Until(t); d > 0 {
+			return d, true
+		}
+		return 0, true
+	}
+
+	return 0, false
+}
+
+// transientStatus reports whether a response status is worth retrying.
+func transientStatus(code int) (bool) {
+	return code == http.StatusTooManyRequests || code >= 500
+}
+
+// sleep waits for a delay or until the context is done.
+func sleep(ctx context.Context, d time.Duration) (error) {
+
+	timer := time.NewTimer(d)
+	defer timer.Stop()
+
+	select {
+	case <-ctx.Done():
+		return ctx.Err()
+	case <-timer.C:
+		return nil
+	}
+}
+
+// ------------------------------------------------------------------------
+// CircuitBreaker.
+// ------------------------------------------------------------------------
+
+// ErrCircuitOpen is returned without contacting the server while a
+// CircuitBreaker is open.
+var ErrCircuitOpen = errors.New(`circuit breaker open: server is failing`)
+
+const (
+	circuitClosed = iota
+	circuitOpen
+	circuitHalfOpen
+)
+
+// CircuitBreaker stops requests to a server that keeps failing. After
+// Threshold consecutive transient failures the circuit opens and requests
+// fail immediately with ErrCircuitOpen. Once Cooldown has passed, one
+// trial request is let through: if it succeeds the circuit closes, if it
+// fails the circuit opens again. A CircuitBreaker may be shared by
+// several clients of the same server.
+type CircuitBreaker struct {
+	Threshold		int
+	Cooldown		time.Duration
+	mutex			sync.Mutex
+	state			int
+	failures		int
+	openedAt		time.Time
+}
+
+// NewCircuitBreaker creates a closed CircuitBreaker.
+func NewCircuitBreaker(threshold int, cooldown time.Duration) (*CircuitBreaker) {
+	return &CircuitBreaker{Threshold: threshold, Cooldown: cooldown}
+}
+
+// WithCircuitBreaker makes the client check a CircuitBreaker before every
+// request and record the outcome.
+func WithCircuitBreaker(cb *CircuitBreaker) (Option) {
+	return func(this *dmnApi) {
+		this.breaker = cb
+	}
+}
+
+// Allow returns ErrCircuitOpen if a request may not be sent now.
+func (this *CircuitBreaker) Allow() (error) {
+
+	this.mutex.Lock()
+	defer this.mutex.Unlock()
+
+	if this.state == circuitClosed {
+		return nil
+	}
+
+	// Let one trial through per cooldown period; a trial whose outcome is
+	// never recorded does not hold the circuit half open forever.
+
+	if time.Since(this.openedAt) < this.Cooldown {
+		return ErrCircuitOpen
+	}
+
+	this.state, this.openedAt = circuitHalfOpen, time.Now()
+	return nil
+}
+
+// Record records the outcome of a request that Allow let through.
+func (this *CircuitBreaker) Record(ok bool) {
+
+	this.mutex.Lock()
+	defer this.mutex.Unlock()
+
+	if ok {
+		this.state, this.failures = circuitClosed, 0
+		return
+	}
+
+	this.failures++
+
+	if this.state == circuitHalfOpen || this.failures >= this.Threshold {
+		this.state, this.openedAt = circuitOpen, time.Now()
+	}
+}
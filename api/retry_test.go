@@ -0,0 +1,107 @@
+package api
+
+import (
+	`net/http`
+	`testing`
+	`time`
+)
+
+func TestRetryDelay(t *testing.T) {
+
+	policy := &RetryPolicy{BaseDelay: time.Second, MaxDelay: 5 * time.Second}
+
+	tests := []struct {
+		attempt		int
+		retryAfter	string
+		want		time.Duration
+	}{
+		{1,	``,		time.Second},
+		{2,	``,		2 * time.Second},
+		{3,	``,		4 * time.Second},
+		{4,	``,		5 * time.Second},
+		{10,	``,		5 * time.Second},
+		{1,	`3`,		3 * time.Second},
+		{1,	`60`,		5 * time.Second},
+		{3,	`0`,		0},
+		{1,	`soon`,		time.Second},
+	}
+
+	for _, tt := range tests {
+
+		header := make(http.Header)
+
+		if tt.retryAfter != `` {
+			header.Set(`Retry-After`, tt.retryAfter)
+		}
+
+		if got := policy.Delay(tt.attempt, header); got != tt.want {
+			t.Errorf(`Delay(%d, %q) = %v, want %v`, tt.attempt, tt.retryAfter, got, tt.want)
+		}
+	}
+}
+
+func TestRetryDelayJitter(t *testing.T) {
+
+	policy := &RetryPolicy{BaseDelay: time.Second, MaxDelay: time.Minute, Jitter: 0.5}
+
+	for i := 0; i < 100; i++ {
+		if got := policy.Delay(1, nil); got < 500 * time.Millisecond || got > time.Second {
+			t.Fatalf(`Delay(1) = %v, want between 500ms and 1s`, got)
+		}
+	}
+}
+
+func TestCircuitBreaker(t *testing.T) {
+
+	tests := []struct {
+		name		string
+		outcomes	[]bool
+		open		bool
+	}{
+		{`no requests`,			nil,					false},
+		{`below threshold`,		[]bool{false, false},			false},
+		{`at threshold`,		[]bool{false, false, false},		true},
+		{`success resets`,		[]bool{false, false, true, false},	false},
+	}
+
+	for _, tt := range tests {
+
+		cb := NewCircuitBreaker(3, time.Hour)
+
+		for _, ok := range tt.outcomes {
+			if err := cb.Allow(); err != nil {
+				t.Fatalf(`%s: Allow = %v`, tt.name, err)
+			}
+			cb.Record(ok)
+		}
+
+		if err := cb.Allow(); (err == ErrCircuitOpen) != tt.open {
+			t.Errorf(`%s: Allow = %v, want open %v`, tt.name, err, tt.open)
+		}
+	}
+}
+
+func TestCircuitBreakerHalfOpen(t *testing.T) {
+
+	cb := NewCircuitBreaker(1, 10 * time.Millisecond)
+
+	cb.Record(false)
+
+	if err := cb.Allow(); err != ErrCircuitOpen {
+		t.Fatalf(`Allow after failure = %v, want %v`, err, ErrCircuitOpen)
+	}
+
+	time.Sleep(20 * time.Millisecond)
+
+	if err := cb.Allow(); err != nil {
+		t.Fatalf(`trial Allow = %v, want nil`, err)
+	} else if err := cb.Allow(); err != ErrCircuitOpen {
+		t.Fatalf(`Allow during trial = %v, want %v`, err, ErrCircuitOpen)
+	}
+
+	cb.Record(false)
+
+	if err := cb.Allow(); err != ErrCircuitOpen {
+		t.Errorf(`Allow after failed trial = %v, want %v`, err, ErrCircuitOpen)
+	}
+}
@@ -0,0 +1,80 @@
+package api
+
+import (
+	`context`
+	`encoding/json`
+	`fmt`
+	`net/http`
+)
+
+// ------------------------------------------------------------------------
+// History Time to Live.
+// ------------------------------------------------------------------------
+
+// historyTtlRequest is the body of a history time to live update. A nil
+// value removes the history time to live of the definition.
+type historyTtlRequest struct {
+	HistoryTtl		*int			`json:"historyTimeToLive"`
+}
+
+// updateHistoryTtl puts the history time to live, in days, to an update
+// endpoint. The cached definition list and map are discarded, since they
+// hold the previous value.
+func (this *dmnApi) updateHistoryTtl(ctx context.Context, url string, ttl *int) (error) {
+
+	if ttl != nil && *ttl < 0 {
+		return fmt.Errorf(`history time to live cannot be negative: %d`, *ttl)
+	}
+
+	b, err := json.Marshal(&historyTtlRequest{ttl})
+
+	if err != nil {
+		return err
+	}
+
+	if _, err := this.send(ctx, http.MethodPut, url, b); err != nil {
+		return err
+	}
+
+	this.listMutex.Lock()
+	this.dmnList = nil
+	this.listMutex.Unlock()
+
+	this.mapMutex.Lock()
+	this.dmnMap = nil
+	this.mapMutex.Unlock()
+
+	return nil
+}
+
+func (this *dmnApi) UpdateHistoryTtlById(id string, ttl *int) (error) {
+	return this.UpdateHistoryTtlByIdContext(context.Background(), id, ttl)
+}
+
+func (this *dmnApi) UpdateHistoryTtlByKey(key string, ttl *int) (error) {
+	return this.UpdateHistoryTtlByKeyContext(context.Background(), key, ttl)
+}
+
+func (this *dmnApi) UpdateHistoryTtlByKeyTenant(key, tenantId string, ttl *int) (error) {
+	return this.UpdateHistoryTtlByKeyTenantContext(context.Background(), key, tenantId, ttl)
+}
+
+func (this *dmnApi) UpdateHistoryTtlByIdContext(ctx context.Context, id string, ttl *int) (error) {
+	return this.updateHistoryTtl(ctx, this.Server + epHistoryTtlById.With(id), ttl)
+}
+
+// The key variants update the latest version of the key only, as the
+// engine does.
+
+func (this *dmnApi) UpdateHistoryTtlByKeyContext(ctx context.Context, key string, ttl *int) (error) {
+	return this.updateHistoryTtl(ctx, this.Server + epHistoryTtlByKey.With(key), ttl)
+}
+
+func (this *dmnApi) UpdateHistoryTtlByKeyTenantContext(ctx context.Context, key, tenantId string, ttl *int) (error) {
+
+	if tenantId == `` {
+		return this.UpdateHistoryTtlByKeyContext(ctx, key, ttl)
+	}
+
+	return this.updateHistoryTtl(ctx, this.Server + epHistoryTtlByKeyTenant.With(key, tenantId), ttl)
+}
@@ -0,0 +1,363 @@
+// Copyright 2018 John Scherff
+//
+// Licensed under the Apache License, version 2.0 (the "License");
+// you may not use this file except in compliance with the License.
+// You may obtain a copy of the License at
+//
+//     http://www.apache.org/licenses/LICENSE-2.0
+//
+// Unless required by applicable law or agreed to in writing, software
+// distributed under the License is distributed on an "AS IS" BASIS,
+// WITHOUT WARRANTIES OR CONDITIONS OF ANY KIND, either express or implied.
+// See the License for the specific language governing permissions and
+// limitations under the License.
+
+// Package feel parses the Friendly Enough Expression Language (FEEL) used
+// in DMN decision tables: the simple unary tests of input entries and the
+// simple expressions of output entries. It produces a syntax tree with
+// source positions and can evaluate the tree against a set of variables.
+package feel
+
+import (
+	`fmt`
+	`strconv`
+	`strings`
+	`time`
+)
+
+// ==============================================================================
+// See https://docs.camunda.org/manual/7.4/reference/dmn11/feel/language-elements/
+// ==============================================================================
+
+// ------------------------------------------------------------------------
+// Node.
+// ------------------------------------------------------------------------
+
+// Node is implemented by every element of a FEEL syntax tree. String
+// returns the canonical FEEL source text of the node.
+type Node interface {
+	Pos() (Pos)
+	String() (string)
+}
+
+// Expr is a node that evaluates to a value: a literal, a name, or an
+// arithmetic expression.
+type Expr interface {
+	Node
+	expr()
+}
+
+// Test is a node that tests a single value: a comparison or an interval.
+type Test interface {
+	Node
+	test()
+}
+
+// ------------------------------------------------------------------------
+// Literals.
+// ------------------------------------------------------------------------
+
+// StringLit is a string literal such as "A".
+type StringLit struct {
+	At			Pos
+	Value			string
+}
+
+// NumberLit is a numeric literal such as 5, -1.5 or .25.
+type NumberLit struct {
+	At			Pos
+	Value			float64
+	Text			string
+}
+
+// BoolLit is the boolean literal true or false.
+type BoolLit struct {
+	At			Pos
+	Value			bool
+}
+
+// NullLit is the null literal.
+type NullLit struct {
+	At			Pos
+}
+
+// Temporal literal kinds.
+const (
+	Date		= `date`
+	Time		= `time`
+	DateTime	= `date and time`
+	Duration	= `duration`
+)
+
+// TemporalLit is a date and time literal such as date("2018-05-03") or
+// duration("P1DT2H"). Value holds a time.Time for dates and times and a
+// time.Duration for durations.
+type TemporalLit struct {
+	At			Pos
+	Kind			string
+	Text			string
+	Value			interface{}
+}
+
+// Name is a reference to a variable, optionally qualified with a path
+// into its fields such as applicant.age.
+type Name struct {
+	At			Pos
+	Parts			[]string
+}
+
+// ------------------------------------------------------------------------
+// Expressions.
+// ------------------------------------------------------------------------
+
+// Negation is the arithmetic negation of an expression.
+type Negation struct {
+	At			Pos
+	X			Expr
+}
+
+// Binary is an arithmetic expression with one of the operators +, -, *,
+// / or **.
+type Binary struct {
+	At			Pos
+	Op			string
+	X			Expr
+	Y			Expr
+}
+
+// ------------------------------------------------------------------------
+// Unary Tests.
+// ------------------------------------------------------------------------
+
+// Comparison tests a value against an endpoint with one of the operators
+// =, <, <=, > or >=. A bare endpoint in a unary test is an = comparison.
+type Comparison struct {
+	At			Pos
+	Op			string
+	Endpoint		Expr
+}
+
+// Interval tests whether a value lies between two endpoints, such as
+// [1..10], ]1..10[ or (1..10).
+type Interval struct {
+	At			Pos
+	OpenStart		bool
+	OpenEnd			bool
+	Start			Expr
+	End			Expr
+}
+
+// UnaryTests is the content of a decision table input entry. Any is set
+// for the irrelevant entry '-' or an empty entry; Not is set when the
+// tests are enclosed in not(...).
+type UnaryTests struct {
+	At			Pos
+	Any			bool
+	Not			bool
+	Tests			[]Test
+}
+
+// ------------------------------------------------------------------------
+// Node Methods.
+// ------------------------------------------------------------------------
+
+func (this *StringLit) Pos() (Pos)	{ return this.At }
+func (this *NumberLit) Pos() (Pos)	{ return this.At }
+func (this *BoolLit) Pos() (Pos)	{ return this.At }
+func (this *NullLit) Pos() (Pos)	{ return this.At }
+func (this *TemporalLit) Pos() (Pos)	{ return this.At }
+func (this *Name) Pos() (Pos)		{ return this.At }
+func (this *Negation) Pos() (Pos)	{ return this.At }
+func (this *Binary) Pos() (Pos)		{ return this.At }
+func (this *Comparison) Pos() (Pos)	{ return this.At }
+func (this *Interval) Pos() (Pos)	{ return this.At }
+func (this *UnaryTests) Pos() (Pos)	{ return this.At }
+
+func (this *StringLit) expr()		{}
+func (this *NumberLit) expr()		{}
+func (this *BoolLit) expr()		{}
+func (this *NullLit) expr()		{}
+func (this *TemporalLit) expr()		{}
+func (this *Name) expr()		{}
+func (this *Negation) expr()		{}
+func (this *Binary) expr()		{}
+
+func (this *Comparison) test()		{}
+func (this *Interval) test()		{}
+
+// String implements the Stringer interface for StringLit.
+func (this *StringLit) String() (string) {
+	return strconv.Quote(this.Value)
+}
+
+// String implements the Stringer interface for NumberLit.
+func (this *NumberLit) String() (string) {
+	return this.Text
+}
+
+// String implements the Stringer interface for BoolLit.
+func (this *BoolLit) String() (string) {
+	return strconv.FormatBool(this.Value)
+}
+
+// String implements the Stringer interface for NullLit.
+func (this *NullLit) String() (string) {
+	return `null`
+}
+
+// String implements the Stringer interface for TemporalLit.
+func (this *TemporalLit) String() (string) {
+	return fmt.Sprintf(`%s(%q)`, this.Kind, this.Text)
+}
+
+// String implements the Stringer interface for Name.
+func (this *Name) String() (string) {
+	return strings.Join(this.Parts, `.`)
+}
+
+// String implements the Stringer interface for Negation.
+func (this *Negation) String() (string) {
+	return `-` + this.X.String()
+}
+
+// String implements the Stringer interface for Binary.
+func (this *Binary) String() (string) {
+	return fmt.Sprintf(`(%s %s %s)`, this.X, this.Op, this.Y)
+}
+
+// String implements the Stringer interface for Comparison.
+func (this *Comparison) String() (string) {
+	if this.Op == `=` {
+		return this.Endpoint.String()
+	}
+	return this.Op + ` ` + this.Endpoint.String()
+}
+
+// String implements the Stringer interface for Interval.
+func (this *Interval) String() (string) {
+
+	start, end := `[`, `]`
+
+	if this.OpenStart {
+		start = `]`
+	}
+	if this.OpenEnd {
+		end = `[`
+	}
+
+	return fmt.Sprintf(`%s%s..%s%s`, start, this.Start, this.End, end)
+}
+
+// String implements the Stringer interface for UnaryTests.
+func (this *UnaryTests) String() (string) {
+
+	if this.Any {
+		return `-`
+	}
+
+	tests := make([]string, len(this.Tests))
+
+	for i, test := range this.Tests {
+		tests[i] = test.String()
+	}
+
+	if list := strings.Join(tests, `, `); this.Not {
+		return `not(` + list + `)`
+	} else {
+		return list
+	}
+}
+
+// ------------------------------------------------------------------------
+// Temporal Literal Helpers.
+// ------------------------------------------------------------------------
+
+// parseTemporal converts the string argument of a date and time literal
+// to a time.Time or time.Duration.
+func parseTemporal(kind, text string) (interface{}, error) {
+
+	var layouts []string
+
+	switch kind {
+	case Date:
+		layouts = []string{`2006-01-02`}
+	case Time:
+		layouts = []string{`15:04:05`, `15:04:05Z07:00`}
+	case DateTime:
+		layouts = []string{`2006-01-02T15:04:05`, time.RFC3339}
+	case Duration:
+		return parseDuration(text)
+	}
+
+	for _, layout := range layouts {
+		if t, err := time.Parse(layout, text); err == nil {
+			return t, nil
+		}
+	}
+
+	return nil, fmt.Errorf(`invalid %s %q`, kind, text)
+}
+
+// parseDuration parses an ISO 8601 days and time duration such as
+// P1DT2H30M or -PT15S. Years and months durations are not supported.
+func parseDuration(text string) (time.Duration, error) {
+
+	s, sign := text, time.Duration(1)
+
+	if strings.HasPrefix(s, `-`) {
+		s, sign = s[1:], -1
+	}
+
+	if !strings.HasPrefix(s, `P`) || len(s) < 2 {
+		return 0, fmt.Errorf(`invalid duration %q`, text)
+	}
+
+	var (
+		d time.Duration
+		inTime bool
+		num string
+	)
+
+	for _, r := range s[1:] {
+
+		switch {
+
+		case r == 'T' && !inTime && num == ``:
+			inTime = true
+
+		case isDigit(r) || r == '.':
+			num += string(r)
+
+		default:
+			f, err := strconv.ParseFloat(num, 64)
+
+			if err != nil {
+				return 0, fmt.Errorf(`invalid duration %q`, text)
+			}
+
+			var unit time.Duration
+
+			switch {
+			case r == 'D' && !inTime:
+				unit = 24 * time.Hour
+			case r == 'H' && inTime:
+				unit = time.Hour
+			case r == 'M' && inTime:
+				unit = time.Minute
+			case r == 'S' && inTime:
+				unit = time.Second
+			default:
+				return 0, fmt.Errorf(`invalid or unsupported duration %q`, text)
+			}
+
+			d += time.Duration(f * float64(unit))
+			num = ``
+		}
+	}
+
+	if num != `` {
+		return 0, fmt.Errorf(`invalid duration %q`, text)
+	}
+
+	return sign * d, nil
+}
@@ -0,0 +1,34 @@
+// Copyright 2018 John Scherff
+//
+// Licensed under the Apache License, version 2.0 (the "License");
+// you may not use this file except in compliance with the License.
+// You may obtain a copy of the License at
+//
+//     http://www.apache.org/licenses/LICENSE-2.0
+//
+// Unless required by applicable law or agreed to in writing, software
+// distributed under the License is distributed on an "AS IS" BASIS,
+// WITHOUT WARRANTIES OR CONDITIONS OF ANY KIND, either express or implied.
+// See the License for the specific language governing permissions and
+// limitations under the License.
+
+package feel
+
+import `fmt`
+
+// ------------------------------------------------------------------------
+// SyntaxError.
+// ------------------------------------------------------------------------
+
+// SyntaxError describes a FEEL syntax error: where it occurred, what was
+// wrong, and the source text in which it occurred.
+type SyntaxError struct {
+	Pos			Pos
+	Msg			string
+	Src			string
+}
+
+// Error implements the error interface for SyntaxError.
+func (this *SyntaxError) Error() (string) {
+	return fmt.Sprintf(`feel: syntax error at %s in %q: %s`, this.Pos, this.Src, this.Msg)
+}
@@ -0,0 +1,393 @@
+// Copyright 2018 John Scherff
+//
+// Licensed under the Apache License, version 2.0 (the "License");
+// you may not use this file except in compliance with the License.
+// You may obtain a copy of the License at
+//
+//     http://www.apache.org/licenses/LICENSE-2.0
+//
+// Unless required by applicable law or agreed to in writing, software
+// distributed under the License is distributed on an "AS IS" BASIS,
+// WITHOUT WARRANTIES OR CONDITIONS OF ANY KIND, either express or implied.
+// See the License for the specific language governing permissions and
+// limitations under the License.
+
+package feel
+
+import (
+	`fmt`
+	`math`
+	`reflect`
+	`strings`
+	`time`
+)
+
+// ------------------------------------------------------------------------
+// Evaluation Functions.
+// ------------------------------------------------------------------------
+
+// Eval evaluates an expression against a set of variables. Numeric
+// literals evaluate to float64, dates and times to time.Time, and
+// durations to time.Duration.
+func Eval(x Expr, vars map[string]interface{}) (interface{}, error) {
+
+	switch x := x.(type) {
+
+	case *StringLit:
+		return x.Value, nil
+
+	case *NumberLit:
+		return x.Value, nil
+
+	case *BoolLit:
+		return x.Value, nil
+
+	case *NullLit:
+		return nil, nil
+
+	case *TemporalLit:
+		return x.Value, nil
+
+	case *Name:
+		return Lookup(x.String(), vars)
+
+	case *Negation:
+		if v, err := Eval(x.X, vars); err != nil {
+			return nil, err
+		} else if f, ok := ToNumber(v); ok {
+			return -f, nil
+		} else if d, ok := v.(time.Duration); ok {
+			return -d, nil
+		} else {
+			return nil, fmt.Errorf(`cannot negate %v (%T)`, v, v)
+		}
+
+	case *Binary:
+		if a, err := Eval(x.X, vars); err != nil {
+			return nil, err
+		} else if b, err := Eval(x.Y, vars); err != nil {
+			return nil, err
+		} else {
+			return arithmetic(x.Op, a, b)
+		}
+
+	default:
+		return nil, fmt.Errorf(`cannot evaluate %T`, x)
+	}
+}
+
+// Match reports whether a value satisfies a set of unary tests. The
+// value satisfies the tests if it satisfies any one of them, or, for
+// negated tests, none of them.
+func Match(ut *UnaryTests, val interface{}, vars map[string]interface{}) (bool, error) {
+
+	if ut.Any {
+		return true, nil
+	}
+
+	for _, test := range ut.Tests {
+		if ok, err := MatchTest(test, val, vars); err != nil {
+			return false, err
+		} else if ok {
+			return !ut.Not, nil
+		}
+	}
+
+	return ut.Not, nil
+}
+
+// MatchTest reports whether a value satisfies a single comparison or
+// interval. A null value satisfies only an equality test against null.
+func MatchTest(test Test, val interface{}, vars map[string]interface{}) (bool, error) {
+
+	switch test := test.(type) {
+
+	case *Comparison:
+
+		ep, err := Eval(test.Endpoint, vars)
+
+		if err != nil {
+			return false, err
+		} else if test.Op == `=` {
+			return Equal(val, ep), nil
+		} else if val == nil || ep == nil {
+			return false, nil
+		}
+
+		c, err := Compare(val, ep)
+
+		if err != nil {
+			return false, err
+		}
+
+		switch test.Op {
+		case `<`:
+			return c < 0, nil
+		case `<=`:
+			return c <= 0, nil
+		case `>`:
+			return c > 0, nil
+		case `>=`:
+			return c >= 0, nil
+		default:
+			return false, fmt.Errorf(`unsupported operator %s`, test.Op)
+		}
+
+	case *Interval:
+
+		if val == nil {
+			return false, nil
+		} else if start, err := Eval(test.Start, vars); err != nil {
+			return false, err
+		} else if end, err := Eval(test.End, vars); err != nil {
+			return false, err
+		} else if cs, err := Compare(val, start); err != nil {
+			return false, err
+		} else if ce, err := Compare(val, end); err != nil {
+			return false, err
+		} else {
+			return (cs > 0 || (cs == 0 && !test.OpenStart)) &&
+				(ce < 0 || (ce == 0 && !test.OpenEnd)), nil
+		}
+
+	default:
+		return false, fmt.Errorf(`cannot match %T`, test)
+	}
+}
+
+// Lookup resolves a variable name, or a dotted path into nested maps,
+// against a set of variables.
+func Lookup(name string, vars map[string]interface{}) (interface{}, error) {
+
+	if val, ok := vars[name]; ok {
+		return val, nil
+	}
+
+	var cur interface{} = vars
+
+	for _, part := range strings.Split(name, `.`) {
+
+		if m, ok := cur.(map[string]interface{}); !ok {
+			return nil, fmt.Errorf(`unknown variable %q`, name)
+		} else if val, ok := m[part]; !ok {
+			return nil, fmt.Errorf(`unknown variable %q`, name)
+		} else {
+			cur = val
+		}
+	}
+
+	return cur, nil
+}
+
+// ------------------------------------------------------------------------
+// Value Functions.
+// ------------------------------------------------------------------------
+
+// Equal reports whether two values are equal, comparing numbers by value
+// regardless of their Go type. Lists and contexts, such as the collected
+// results of a required decision, are equal when their elements are.
+// Values of other types that Go cannot compare with == are compared deeply.
+func Equal(a, b interface{}) (bool) {
+
+	if a == nil || b == nil {
+		return a == nil && b == nil
+	} else if c, err := Compare(a, b); err == nil {
+		return c == 0
+	}
+
+	switch va := a.(type) {
+
+	case []interface{}:
+
+		vb, ok := b.([]interface{})
+
+		if !ok || len(va) != len(vb) {
+			return false
+		}
+
+		for i := range va {
+			if !Equal(va[i], vb[i]) {
+				return false
+			}
+		}
+
+		return true
+
+	case map[string]interface{}:
+
+		vb, ok := b.(map[string]interface{})
+
+		if !ok || len(va) != len(vb) {
+			return false
+		}
+
+		for k, v := range va {
+			if w, ok := vb[k]; !ok || !Equal(v, w) {
+				return false
+			}
+		}
+
+		return true
+	}
+
+	if ta, tb := reflect.TypeOf(a), reflect.TypeOf(b); ta != tb {
+		return false
+	} else if !ta.Comparable() {
+		return reflect.DeepEqual(a, b)
+	}
+
+	return a == b
+}
+
+// Compare orders two numbers, strings, dates or durations, returning -1,
+// 0 or 1. Booleans compare equal only to the same boolean.
+func Compare(a, b interface{}) (int, error) {
+
+	if fa, ok := ToNumber(a); ok {
+		if fb, ok := ToNumber(b); ok {
+			return compareFloat(fa, fb), nil
+		}
+	}
+
+	switch va := a.(type) {
+
+	case string:
+		if vb, ok := b.(string); ok {
+			return strings.Compare(va, vb), nil
+		}
+
+	case time.Time:
+		if vb, ok := b.(time.Time); ok {
+			switch {
+			case va.Before(vb):
+				return -1, nil
+			case va.After(vb):
+				return 1, nil
+			default:
+				return 0, nil
+			}
+		}
+
+	case time.Duration:
+		if vb, ok := b.(time.Duration); ok {
+			return compareFloat(float64(va), float64(vb)), nil
+		}
+
+	case bool:
+		if vb, ok := b.(bool); ok && va == vb {
+			return 0, nil
+		}
+	}
+
+	return 0, fmt.Errorf(`cannot compare %v (%T) with %v (%T)`, a, a, b, b)
+}
+
+// ToNumber converts any Go integer or floating-point value to float64.
+func ToNumber(val interface{}) (float64, bool) {
+
+	switch v := val.(type) {
+	case int:
+		return float64(v), true
+	case int8:
+		return float64(v), true
+	case int16:
+		return float64(v), true
+	case int32:
+		return float64(v), true
+	case int64:
+		return float64(v), true
+	case uint:
+		return float64(v), true
+	case uint8:
+		return float64(v), true
+	case uint16:
+		return float64(v), true
+	case uint32:
+		return float64(v), true
+	case uint64:
+		return float64(v), true
+	case float32:
+		return float64(v), true
+	case float64:
+		return v, true
+	}
+
+	return 0, false
+}
+
+func compareFloat(a, b float64) (int) {
+	switch {
+	case a < b:
+		return -1
+	case a > b:
+		return 1
+	default:
+		return 0
+	}
+}
+
+// arithmetic applies an arithmetic operator to two values.
+func arithmetic(op string, a, b interface{}) (interface{}, error) {
+
+	if a == nil || b == nil {
+		return nil, nil
+	}
+
+	if fa, ok := ToNumber(a); ok {
+		if fb, ok := ToNumber(b); ok {
+			switch op {
+			case `+`:
+				return fa + fb, nil
+			case `-`:
+				return fa - fb, nil
+			case `*`:
+				return fa * fb, nil
+			case `/`:
+				if fb == 0 {
+					return nil, nil
+				}
+				return fa / fb, nil
+			case `**`:
+				return math.Pow(fa, fb), nil
+			}
+		}
+	}
+
+	switch va := a.(type) {
+
+	case string:
+		if vb, ok := b.(string); ok && op == `+` {
+			return va + vb, nil
+		}
+
+	case time.Time:
+		switch vb := b.(type) {
+		case time.Duration:
+			if op == `+` {
+				return va.Add(vb), nil
+			} else if op == `-` {
+				return va.Add(-vb), nil
+			}
+		case time.Time:
+			if op == `-` {
+				return va.Sub(vb), nil
+			}
+		}
+
+	case time.Duration:
+		switch vb := b.(type) {
+		case time.Duration:
+			if op == `+` {
+				return va + vb, nil
+			} else if op == `-` {
+				return va - vb, nil
+			}
+		case time.Time:
+			if op == `+` {
+				return vb.Add(va), nil
+			}
+		}
+	}
+
+	return nil, fmt.Errorf(`cannot apply %s to %v (%T) and %v (%T)`, op, a, a, b, b)
+}
@@ -0,0 +1,123 @@
+package feel
+
+import (
+	`testing`
+)
+
+func TestEval(t *testing.T) {
+
+	vars := map[string]interface{}{
+		`amount`: 10,
+		`customer`: map[string]interface{}{`age`: 30},
+	}
+
+	tests := []struct {
+		src	string
+		want	interface{}
+	}{
+		{`1 + 2 * 3`,		7.0},
+		{`(1 + 2) * 3`,		9.0},
+		{`2 ** 3 ** 2`,		512.0},
+		{`-2 ** 2`,		-4.0},
+		{`(-2) ** 2`,		4.0},
+		{`2 ** -1`,		0.5},
+		{`- -3`,		3.0},
+		{`-amount`,		-10.0},
+		{`10 - 4 - 3`,		3.0},
+		{`amount / 4`,		2.5},
+		{`customer.age + 1`,	31.0},
+		{`"a" + "b"`,		`ab`},
+		{`null`,		nil},
+		{`true`,		true},
+	}
+
+	for _, tt := range tests {
+		if x, err := ParseExpression(tt.src); err != nil {
+			t.Errorf(`ParseExpression(%q): %v`, tt.src, err)
+		} else if got, err := Eval(x, vars); err != nil {
+			t.Errorf(`Eval(%q): %v`, tt.src, err)
+		} else if !Equal(got, tt.want) {
+			t.Errorf(`Eval(%q) = %v, want %v`, tt.src, got, tt.want)
+		}
+	}
+}
+
+func TestParseExpressionErrors(t *testing.T) {
+
+	for _, src := range []string{``, `1 +`, `(1`, `1 2`, `*`} {
+		if _, err := ParseExpression(src); err == nil {
+			t.Errorf(`ParseExpression(%q) succeeded, want error`, src)
+		}
+	}
+}
+
+func TestMatch(t *testing.T) {
+
+	tests := []struct {
+		src	string
+		val	interface{}
+		want	bool
+	}{
+		{``,			42,		true},
+		{`-`,			nil,		true},
+		{`5`,			5.0,		true},
+		{`5`,			6,		false},
+		{`< 10`,		9,		true},
+		{`< 10`,		10,		false},
+		{`<= 10`,		10,		true},
+		{`[1..10]`,		10,		true},
+		{`[1..10[`,		10,		false},
+		{`]1..10]`,		1,		false},
+		{`-5`,			-5,		true},
+		{`[-5..-1]`,		-3,		true},
+		{`"a", "b"`,		`b`,		true},
+		{`not("a", "b")`,	`c`,		true},
+		{`not("a", "b")`,	`a`,		false},
+		{`null`,		nil,		true},
+		{`"a"`,			nil,		false},
+		{`"a"`,			[]interface{}{`a`},	false},
+	}
+
+	for _, tt := range tests {
+		if ut, err := ParseUnaryTests(tt.src); err != nil {
+			t.Errorf(`ParseUnaryTests(%q): %v`, tt.src, err)
+		} else if got, err := Match(ut, tt.val, nil); err != nil {
+			t.Errorf(`Match(%q, %v): %v`, tt.src, tt.val, err)
+		} else if got != tt.want {
+			t.Errorf(`Match(%q, %v) = %v, want %v`, tt.src, tt.val, got, tt.want)
+		}
+	}
+}
+
+func TestEqual(t *testing.T) {
+
+	type pair struct{ a, b int }
+
+	tests := []struct {
+		a, b	interface{}
+		want	bool
+	}{
+		{nil,					nil,					true},
+		{nil,					0,					false},
+		{1,					1.0,					true},
+		{`a`,					`a`,					true},
+		{`a`,					1,					false},
+		{[]interface{}{1, `a`},			[]interface{}{1.0, `a`},		true},
+		{[]interface{}{1, `a`},			[]interface{}{1, `b`},			false},
+		{[]interface{}{1},			[]interface{}{1, 2},			false},
+		{[]interface{}{1},			1,					false},
+		{[]interface{}{[]interface{}{1}},	[]interface{}{[]interface{}{1.0}},	true},
+		{map[string]interface{}{`x`: 1},	map[string]interface{}{`x`: 1.0},	true},
+		{map[string]interface{}{`x`: 1},	map[string]interface{}{`y`: 1},		false},
+		{[]string{`a`},				[]string{`a`},				true},
+		{[]string{`a`},				[]string{`b`},				false},
+		{pair{1, 2},				pair{1, 2},				true},
+		{pair{1, 2},				&pair{1, 2},				false},
+	}
+
+	for _, tt := range tests {
+		if got := Equal(tt.a, tt.b); got != tt.want {
+			t.Errorf(`Equal(%v, %v) = %v, want %v`, tt.a, tt.b, got, tt.want)
+		}
+	}
+}
@@ -0,0 +1,441 @@
+// Copyright 2018 John Scherff
+//
+// Licensed under the Apache License, version 2.0 (the "License");
+// you may not use this file except in compliance with the License.
+// You may obtain a copy of the License at
+//
+//     http://www.apache.org/licenses/LICENSE-2.0
+//
+// Unless required by applicable law or agreed to in writing, software
+// distributed under the License is distributed on an "AS IS" BASIS,
+// WITHOUT WARRANTIES OR CONDITIONS OF ANY KIND, either express or implied.
+// See the License for the specific language governing permissions and
+// limitations under the License.
+
+package feel
+
+import (
+	`fmt`
+	`strconv`
+)
+
+// ------------------------------------------------------------------------
+// Parser Functions.
+// ------------------------------------------------------------------------
+
+// ParseUnaryTests parses FEEL simple unary tests, the language of decision
+// table input entries and allowed-value lists:
+//
+//	simple unary tests	= "-" | tests | "not(" tests ")"
+//	tests			= test { "," test }
+//	test			= [ "<" | "<=" | ">" | ">=" ] endpoint | interval
+//	interval		= ( "[" | "]" | "(" ) endpoint ".." endpoint ( "]" | "[" | ")" )
+//	endpoint		= qualified name | simple literal
+//
+// Empty text is treated like "-" and matches any value.
+func ParseUnaryTests(src string) (*UnaryTests, error) {
+
+	p, err := newParser(src)
+
+	if err != nil {
+		return nil, err
+	}
+
+	ut := &UnaryTests{At: p.peek().Pos}
+
+	switch {
+
+	case p.peek().Kind == EOF:
+		ut.Any = true
+		return ut, nil
+
+	case p.peek().is(`-`) && p.peekAt(1).Kind == EOF:
+		p.next()
+		ut.Any = true
+		return ut, nil
+
+	case p.peek().is(`not`) && p.peekAt(1).is(`(`):
+		p.next()
+		p.next()
+		ut.Not = true
+	}
+
+	if ut.Tests, err = p.parseTests(); err != nil {
+		return nil, err
+	}
+
+	if ut.Not {
+		if _, err := p.expect(`)`); err != nil {
+			return nil, err
+		}
+	}
+
+	if err := p.expectEOF(); err != nil {
+		return nil, err
+	}
+
+	return ut, nil
+}
+
+// ParseExpression parses a FEEL simple expression, the language of
+// decision table output entries and input expressions:
+//
+//	expression	= term { ( "+" | "-" ) term }
+//	term		= unary { ( "*" | "/" ) unary }
+//	unary		= "-" unary | power
+//	power		= primary [ "**" unary ]
+//	primary		= simple literal | qualified name | "(" expression ")"
+//
+// Simple literals are strings, numbers, true, false, null and the date
+// and time literals date("..."), time("..."), date and time("...") and
+// duration("...").
+func ParseExpression(src string) (Expr, error) {
+
+	p, err := newParser(src)
+
+	if err != nil {
+		return nil, err
+	}
+
+	if p.peek().Kind == EOF {
+		return nil, p.errorf(p.peek(), `empty expression`)
+	}
+
+	if x, err := p.parseExpr(); err != nil {
+		return nil, err
+	} else if err := p.expectEOF(); err != nil {
+		return nil, err
+	} else {
+		return x, nil
+	}
+}
+
+// ------------------------------------------------------------------------
+// Parser.
+// ------------------------------------------------------------------------
+
+// parser is a recursive-descent parser over the tokens of FEEL source.
+type parser struct {
+	src			string
+	toks			[]Token
+	pos			int
+}
+
+func newParser(src string) (*parser, error) {
+
+	if toks, err := lex(src); err != nil {
+		return nil, err
+	} else {
+		return &parser{src: src, toks: toks}, nil
+	}
+}
+
+// peek returns the current token without consuming it.
+func (this *parser) peek() (Token) {
+	return this.peekAt(0)
+}
+
+// peekAt returns the token n positions ahead of the current token.
+func (this *parser) peekAt(n int) (Token) {
+	if this.pos+n < len(this.toks) {
+		return this.toks[this.pos+n]
+	}
+	return this.toks[len(this.toks)-1]
+}
+
+// next consumes and returns the current token.
+func (this *parser) next() (Token) {
+	tok := this.peek()
+	if this.pos < len(this.toks)-1 {
+		this.pos++
+	}
+	return tok
+}
+
+// expect consumes the current token if it is the given punctuation.
+func (this *parser) expect(value string) (Token, error) {
+	if tok := this.next(); !tok.is(value) {
+		return tok, this.errorf(tok, `expected '%s', found %s`, value, tok)
+	} else {
+		return tok, nil
+	}
+}
+
+// expectEOF returns an error unless all tokens have been consumed.
+func (this *parser) expectEOF() (error) {
+	if tok := this.peek(); tok.Kind != EOF {
+		return this.errorf(tok, `unexpected %s`, tok)
+	}
+	return nil
+}
+
+// errorf returns a SyntaxError positioned at the given token.
+func (this *parser) errorf(tok Token, format string, args ...interface{}) (error) {
+	return &SyntaxError{tok.Pos, fmt.Sprintf(format, args...), this.src}
+}
+
+// ------------------------------------------------------------------------
+// Unary Test Productions.
+// ------------------------------------------------------------------------
+
+func (this *parser) parseTests() (tests []Test, err error) {
+
+	for {
+		if test, err := this.parseTest(); err != nil {
+			return nil, err
+		} else {
+			tests = append(tests, test)
+		}
+
+		if !this.peek().is(`,`) {
+			return tests, nil
+		}
+
+		this.next()
+	}
+}
+
+func (this *parser) parseTest() (Test, error) {
+
+	tok := this.peek()
+
+	switch {
+
+	case tok.is(`<`), tok.is(`<=`), tok.is(`>`), tok.is(`>=`):
+		this.next()
+		if ep, err := this.parseEndpoint(); err != nil {
+			return nil, err
+		} else {
+			return &Comparison{tok.Pos, tok.Value, ep}, nil
+		}
+
+	case tok.is(`[`), tok.is(`]`), tok.is(`(`):
+		return this.parseInterval()
+
+	default:
+		if ep, err := this.parseEndpoint(); err != nil {
+			return nil, err
+		} else {
+			return &Comparison{tok.Pos, `=`, ep}, nil
+		}
+	}
+}
+
+func (this *parser) parseInterval() (Test, error) {
+
+	tok := this.next()
+	iv := &Interval{At: tok.Pos, OpenStart: !tok.is(`[`)}
+
+	var err error
+
+	if iv.Start, err = this.parseEndpoint(); err != nil {
+		return nil, err
+	} else if _, err = this.expect(`..`); err != nil {
+		return nil, err
+	} else if iv.End, err = this.parseEndpoint(); err != nil {
+		return nil, err
+	}
+
+	switch end := this.next(); {
+	case end.is(`]`):
+	case end.is(`[`), end.is(`)`):
+		iv.OpenEnd = true
+	default:
+		return nil, this.errorf(end, `expected ']', '[' or ')', found %s`, end)
+	}
+
+	return iv, nil
+}
+
+// parseEndpoint parses a simple value: a simple literal or a qualified name.
+func (this *parser) parseEndpoint() (Expr, error) {
+
+	tok := this.peek()
+
+	switch {
+	case tok.Kind == NUMBER, tok.Kind == STRING, tok.Kind == NAME:
+		return this.parsePrimary()
+	case tok.is(`-`) && this.peekAt(1).Kind == NUMBER:
+		return this.parsePrimary()
+	default:
+		return nil, this.errorf(tok, `expected literal or name, found %s`, tok)
+	}
+}
+
+// ------------------------------------------------------------------------
+// Expression Productions.
+// ------------------------------------------------------------------------
+
+func (this *parser) parseExpr() (Expr, error) {
+
+	x, err := this.parseTerm()
+
+	for err == nil && (this.peek().is(`+`) || this.peek().is(`-`)) {
+		op := this.next()
+		var y Expr
+		if y, err = this.parseTerm(); err == nil {
+			x = &Binary{op.Pos, op.Value, x, y}
+		}
+	}
+
+	return x, err
+}
+
+func (this *parser) parseTerm() (Expr, error) {
+
+	x, err := this.parseUnary()
+
+	for err == nil && (this.peek().is(`*`) || this.peek().is(`/`)) {
+		op := this.next()
+		var y Expr
+		if y, err = this.parseUnary(); err == nil {
+			x = &Binary{op.Pos, op.Value, x, y}
+		}
+	}
+
+	return x, err
+}
+
+// parseUnary parses an optionally negated power. Negation binds more
+// loosely than exponentiation, so -2 ** 2 is -(2 ** 2). A negative number
+// that is not a base is folded into a single literal.
+func (this *parser) parseUnary() (Expr, error) {
+
+	if tok := this.peek(); tok.is(`-`) {
+		if this.peekAt(1).Kind == NUMBER && !this.peekAt(2).is(`**`) {
+			return this.parsePrimary()
+		}
+		this.next()
+		if x, err := this.parseUnary(); err != nil {
+			return nil, err
+		} else {
+			return &Negation{tok.Pos, x}, nil
+		}
+	}
+
+	return this.parsePower()
+}
+
+func (this *parser) parsePower() (Expr, error) {
+
+	x, err := this.parsePrimary()
+
+	if err == nil && this.peek().is(`**`) {
+		op := this.next()
+		var y Expr
+		if y, err = this.parseUnary(); err == nil {
+			x = &Binary{op.Pos, op.Value, x, y}
+		}
+	}
+
+	return x, err
+}
+
+func (this *parser) parsePrimary() (Expr, error) {
+
+	tok := this.next()
+
+	switch tok.Kind {
+
+	case STRING:
+		return &StringLit{tok.Pos, tok.Value}, nil
+
+	case NUMBER:
+		return this.number(tok, tok.Value)
+
+	case NAME:
+		return this.parseName(tok)
+
+	case PUNCT:
+		switch {
+
+		case tok.is(`-`) && this.peek().Kind == NUMBER:
+			return this.number(tok, `-` + this.next().Value)
+
+		case tok.is(`(`):
+			if x, err := this.parseExpr(); err != nil {
+				return nil, err
+			} else if _, err := this.expect(`)`); err != nil {
+				return nil, err
+			} else {
+				return x, nil
+			}
+		}
+	}
+
+	return nil, this.errorf(tok, `expected expression, found %s`, tok)
+}
+
+// number converts the text of a numeric literal to a NumberLit.
+func (this *parser) number(tok Token, text string) (Expr, error) {
+	if f, err := strconv.ParseFloat(text, 64); err != nil {
+		return nil, this.errorf(tok, `invalid number %s`, text)
+	} else {
+		return &NumberLit{tok.Pos, f, text}, nil
+	}
+}
+
+// parseName parses keywords, date and time literals and qualified names
+// beginning with the given name token.
+func (this *parser) parseName(tok Token) (Expr, error) {
+
+	switch tok.Value {
+
+	case `true`, `false`:
+		return &BoolLit{tok.Pos, tok.Value == `true`}, nil
+
+	case `null`:
+		return &NullLit{tok.Pos}, nil
+
+	case `not`:
+		return nil, this.errorf(tok, `unexpected 'not'`)
+
+	case Date:
+		if this.peek().is(`and`) && this.peekAt(1).is(`time`) && this.peekAt(2).is(`(`) {
+			this.next()
+			this.next()
+			return this.parseTemporal(tok, DateTime)
+		} else if this.peek().is(`(`) {
+			return this.parseTemporal(tok, Date)
+		}
+
+	case Time, Duration:
+		if this.peek().is(`(`) {
+			return this.parseTemporal(tok, tok.Value)
+		}
+	}
+
+	name := &Name{tok.Pos, []string{tok.Value}}
+
+	for this.peek().is(`.`) {
+		this.next()
+		if part := this.next(); part.Kind != NAME {
+			return nil, this.errorf(part, `expected name, found %s`, part)
+		} else {
+			name.Parts = append(name.Parts, part.Value)
+		}
+	}
+
+	return name, nil
+}
+
+// parseTemporal parses the parenthesized string argument of a date and
+// time literal.
+func (this *parser) parseTemporal(tok Token, kind string) (Expr, error) {
+
+	if _, err := this.expect(`(`); err != nil {
+		return nil, err
+	}
+
+	arg := this.next()
+
+	if arg.Kind != STRING {
+		return nil, this.errorf(arg, `expected string, found %s`, arg)
+	} else if _, err := this.expect(`)`); err != nil {
+		return nil, err
+	} else if val, err := parseTemporal(kind, arg.Value); err != nil {
+		return nil, this.errorf(arg, `%v`, err)
+	} else {
+		return &TemporalLit{tok.Pos, kind, arg.Value, val}, nil
+	}
+}
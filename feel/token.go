@@ -0,0 +1,253 @@
+// Copyright 2018 John Scherff
+//
+// Licensed under the Apache License, version 2.0 (the "License");
+// you may not use this file except in compliance with the License.
+// You may obtain a copy of the License at
+//
+//     http://www.apache.org/licenses/LICENSE-2.0
+//
+// Unless required by applicable law or agreed to in writing, software
+// distributed under the License is distributed on an "AS IS" BASIS,
+// WITHOUT WARRANTIES OR CONDITIONS OF ANY KIND, either express or implied.
+// See the License for the specific language governing permissions and
+// limitations under the License.
+
+package feel
+
+import (
+	`fmt`
+	`strings`
+	`unicode`
+	`unicode/utf8`
+)
+
+// ------------------------------------------------------------------------
+// Pos.
+// ------------------------------------------------------------------------
+
+// Pos identifies a position in FEEL source text. Offset is the byte
+// offset from the start of the text; Line and Column are 1-based.
+type Pos struct {
+	Offset			int
+	Line			int
+	Column			int
+}
+
+// String implements the Stringer interface for Pos.
+func (this Pos) String() (string) {
+	return fmt.Sprintf(`%d:%d`, this.Line, this.Column)
+}
+
+// ------------------------------------------------------------------------
+// Token.
+// ------------------------------------------------------------------------
+
+// TokenKind classifies the lexical tokens of FEEL source text.
+type TokenKind int
+
+const (
+	EOF TokenKind = iota
+	NAME
+	STRING
+	NUMBER
+	PUNCT
+)
+
+// String implements the Stringer interface for TokenKind.
+func (this TokenKind) String() (string) {
+	switch this {
+	case EOF:
+		return `end of input`
+	case NAME:
+		return `name`
+	case STRING:
+		return `string`
+	case NUMBER:
+		return `number`
+	case PUNCT:
+		return `punctuation`
+	default:
+		return `unknown`
+	}
+}
+
+// Token is a lexical token of FEEL source text. For STRING tokens Value
+// holds the unquoted string; for all others it holds the source text.
+type Token struct {
+	Kind			TokenKind
+	Value			string
+	Pos			Pos
+}
+
+// String implements the Stringer interface for Token.
+func (this Token) String() (string) {
+	switch this.Kind {
+	case EOF:
+		return this.Kind.String()
+	case STRING:
+		return fmt.Sprintf(`%q`, this.Value)
+	default:
+		return fmt.Sprintf(`'%s'`, this.Value)
+	}
+}
+
+// is reports whether the Token is the given punctuation or name.
+func (this Token) is(value string) (bool) {
+	return (this.Kind == PUNCT || this.Kind == NAME) && this.Value == value
+}
+
+// ------------------------------------------------------------------------
+// Lexer.
+// ------------------------------------------------------------------------
+
+// punctuation lists the FEEL operators and delimiters, longest first.
+var punctuation = []string{
+	`**`, `..`, `<=`, `>=`, `!=`,
+	`<`, `>`, `=`, `(`, `)`, `[`, `]`, `,`, `-`, `+`, `*`, `/`, `.`,
+}
+
+// lex splits FEEL source text into tokens, terminated by an EOF token.
+func lex(src string) ([]Token, error) {
+
+	var toks []Token
+
+	pos := Pos{0, 1, 1}
+
+	advance := func(n int) {
+		for _, r := range src[pos.Offset:pos.Offset+n] {
+			if r == '\n' {
+				pos.Line++
+				pos.Column = 1
+			} else {
+				pos.Column++
+			}
+		}
+		pos.Offset += n
+	}
+
+	for {
+		for pos.Offset < len(src) {
+			if r, n := utf8.DecodeRuneInString(src[pos.Offset:]); unicode.IsSpace(r) {
+				advance(n)
+			} else {
+				break
+			}
+		}
+
+		if pos.Offset >= len(src) {
+			return append(toks, Token{EOF, ``, pos}), nil
+		}
+
+		rest, start := src[pos.Offset:], pos
+		r, _ := utf8.DecodeRuneInString(rest)
+
+		switch {
+
+		case r == '"':
+			if value, n, err := lexString(rest); err != nil {
+				return nil, &SyntaxError{start, err.Error(), src}
+			} else {
+				toks = append(toks, Token{STRING, value, start})
+				advance(n)
+			}
+
+		case isDigit(r) || (r == '.' && len(rest) > 1 && isDigit(rune(rest[1]))):
+			n := lexNumber(rest)
+			toks = append(toks, Token{NUMBER, rest[:n], start})
+			advance(n)
+
+		case isNameStart(r):
+			n := 0
+			for n < len(rest) {
+				if r, size := utf8.DecodeRuneInString(rest[n:]); isNamePart(r) {
+					n += size
+				} else {
+					break
+				}
+			}
+			toks = append(toks, Token{NAME, rest[:n], start})
+			advance(n)
+
+		default:
+			found := false
+			for _, p := range punctuation {
+				if strings.HasPrefix(rest, p) {
+					toks = append(toks, Token{PUNCT, p, start})
+					advance(len(p))
+					found = true
+					break
+				}
+			}
+			if !found {
+				return nil, &SyntaxError{start, fmt.Sprintf(`unexpected character %q`, r), src}
+			}
+		}
+	}
+}
+
+// lexString scans a double-quoted string literal at the start of s and
+// returns its unquoted value and length.
+func lexString(s string) (string, int, error) {
+
+	var sb strings.Builder
+
+	for i := 1; i < len(s); i++ {
+
+		switch c := s[i]; c {
+
+		case '"':
+			return sb.String(), i + 1, nil
+
+		case '\\':
+			if i+1 >= len(s) {
+				return ``, 0, fmt.Errorf(`unterminated string literal`)
+			}
+			i++
+			switch s[i] {
+			case 'n':
+				sb.WriteByte('\n')
+			case 't':
+				sb.WriteByte('\t')
+			case 'r':
+				sb.WriteByte('\r')
+			default:
+				sb.WriteByte(s[i])
+			}
+
+		default:
+			sb.WriteByte(c)
+		}
+	}
+
+	return ``, 0, fmt.Errorf(`unterminated string literal`)
+}
+
+// lexNumber returns the length of the numeric literal at the start of s.
+// A trailing '.' is not consumed when it begins a '..' range operator.
+func lexNumber(s string) (n int) {
+
+	for n < len(s) && isDigit(rune(s[n])) {
+		n++
+	}
+
+	if n < len(s) && s[n] == '.' && n+1 < len(s) && isDigit(rune(s[n+1])) {
+		n++
+		for n < len(s) && isDigit(rune(s[n])) {
+			n++
+		}
+	}
+
+	return n
+}
+
+func isDigit(r rune) (bool) {
+	return r >= '0' && r <= '9'
+}
+
+func isNameStart(r rune) (bool) {
+	return r == '_' || r == '$' || unicode.IsLetter(r)
+}
+
+func isNamePart(r rune) (bool) {
+	return isNameStart(r) || unicode.IsDigit(r)
+}
@@ -0,0 +1,648 @@
+// Copyright 2018 John Scherff
+//
+// Licensed under the Apache License, version 2.0 (the "License");
+// you may not use this file except in compliance with the License.
+// You may obtain a copy of the License at
+//
+//     http://www.apache.org/licenses/LICENSE-2.0
+//
+// Unless required by applicable law or agreed to in writing, software
+// distributed under the License is distributed on an "AS IS" BASIS,
+// WITHOUT WARRANTIES OR CONDITIONS OF ANY KIND, either express or implied.
+// See the License for the specific language governing permissions and
+// limitations under the License.
+
+package model
+
+import (
+	`bytes`
+	`encoding/xml`
+	`fmt`
+	`io`
+	`strings`
+)
+
+// ==============================================================================
+// See https://docs.camunda.org/manual/7.13/update/minor/712-to-713/#dmn-1-3
+// ==============================================================================
+
+// requirementTags are the local names of the requirement elements, which
+// have an id since DMN 1.3.
+var requirementTags = map[string]bool{
+	`informationRequirement`: true,
+	`knowledgeRequirement`:   true,
+	`authorityRequirement`:   true,
+}
+
+// feelNamespaces are the FEEL namespaces replaced by the DMN 1.3 one.
+var feelNamespaces = map[string]bool{
+	NsFeel11: true,
+	NsFeel12: true,
+}
+
+// ------------------------------------------------------------------------
+// DMN Conversion.
+// ------------------------------------------------------------------------
+
+// UpgradeDmn converts DMN 1.1 or DMN 1.2 XML from a Reader, url, file or
+// string to DMN 1.3, as required by Camunda 7.13 and later. It replaces
+// the DMN, DMNDI and FEEL namespaces, removes the feel: prefix from type
+// references, gives requirements without an id a generated one, and moves
+// the diagram data the Camunda Modeler keeps in biodi extension elements
+// of DMN 1.1 models to a DMNDI diagram. Everything else, including
+// comments and Camunda extension attributes, is kept as written. DMN 1.3
+// XML is returned unchanged.
+func UpgradeDmn(src interface{}) ([]byte, error) {
+
+	buf := new(bytes.Buffer)
+
+	switch obj := src.(type) {
+
+	case io.Reader:
+		if _, err := io.Copy(buf, obj); err != nil {
+			return nil, err
+		}
+
+	case string:
+		if _, err := read(buf, obj); err != nil {
+			return nil, err
+		}
+
+	default:
+		return nil, fmt.Errorf(`unsupported source: %T`, obj)
+	}
+
+	b := buf.Bytes()
+	prolog, root, err := parseXmlTree(b)
+
+	if err != nil {
+		return nil, err
+	} else if root.name.Local != `definitions` {
+		return nil, fmt.Errorf(`root element is %s, not definitions`, root.name.Local)
+	}
+
+	if version, err := DmnVersion(root.namespace(root.name.Space)); err != nil {
+		return nil, err
+	} else if version == DmnVersion13 {
+		return b, nil
+	}
+
+	c := &converter{
+		dmn: root.name.Space,
+		ids: make(map[string]bool),
+		biodi: make(map[string]bool),
+	}
+
+	root.each(func(n *xmlNode) {
+		if id := n.attr(`id`); id != `` {
+			c.ids[id] = true
+		}
+	})
+
+	c.convert(root)
+
+	if err := c.addDiagram(root); err != nil {
+		return nil, err
+	}
+
+	out := new(bytes.Buffer)
+
+	for _, tok := range prolog {
+		writeXmlToken(out, tok)
+	}
+
+	root.write(out)
+	out.WriteString("\n")
+
+	// The result must parse as DMN 1.3.
+
+	if _, err := NewDmn(bytes.NewReader(out.Bytes())); err != nil {
+		return nil, fmt.Errorf(`converted DMN does not parse: %v`, err)
+	}
+
+	return out.Bytes(), nil
+}
+
+// ------------------------------------------------------------------------
+// converter.
+// ------------------------------------------------------------------------
+
+// converter holds the state of a conversion: the prefix of the DMN
+// namespace, the ids in use, the prefixes bound to the biodi namespace,
+// and the DMNDI shapes and edges collected from biodi elements.
+type converter struct {
+	dmn			string
+	ids			map[string]bool
+	biodi			map[string]bool
+	shapes			[]*xmlNode
+	edges			[]*xmlNode
+}
+
+// id returns an unused id, preferably the given one, and marks it used.
+func (this *converter) id(preferred, prefix string) (string) {
+
+	id := preferred
+
+	for n := 1; id == `` || this.ids[id]; n++ {
+		id = fmt.Sprintf(`%s_%d`, prefix, n)
+	}
+
+	this.ids[id] = true
+
+	return id
+}
+
+// convert upgrades an element and its children.
+func (this *converter) convert(n *xmlNode) {
+
+	var attrs []xml.Attr
+
+	for _, a := range n.attrs {
+
+		switch {
+
+		case isXmlnsAttr(a) && a.Value == NsBiodi:
+			this.biodi[a.Name.Local] = true
+			continue
+
+		case isXmlnsAttr(a) && (a.Value == NsDmn11 || a.Value == NsDmn12):
+			a.Value = NsDmn13
+
+		case isXmlnsAttr(a) && a.Value == NsDmndi12:
+			a.Value = NsDmndi13
+
+		case isXmlnsAttr(a) && feelNamespaces[a.Value]:
+			a.Value = NsFeel13
+
+		case a.Name.Local == `expressionLanguage` || a.Name.Local == `typeLanguage`:
+			if feelNamespaces[a.Value] {
+				a.Value = NsFeel13
+			}
+
+		case a.Name.Local == `typeRef`:
+			a.Value = strings.TrimPrefix(a.Value, `feel:`)
+		}
+
+		attrs = append(attrs, a)
+	}
+
+	n.attrs = attrs
+
+	if requirementTags[n.name.Local] && n.attr(`id`) == `` {
+		tag := strings.ToUpper(n.name.Local[:1]) + n.name.Local[1:]
+		n.attrs = append([]xml.Attr{{Name: xml.Name{Local: `id`}, Value: this.id(``, tag)}}, n.attrs...)
+	}
+
+	for _, c := range n.elements() {
+		this.convert(c)
+	}
+
+	for _, ext := range n.elements() {
+
+		if ext.name.Local != `extensionElements` || ext.name.Space != this.dmn {
+			continue
+		}
+
+		for _, c := range ext.elements() {
+
+			if !this.biodi[c.name.Space] {
+				continue
+			}
+
+			switch c.name.Local {
+			case `bounds`:
+				this.addShape(n, c)
+			case `edge`:
+				this.addEdge(n, c)
+			}
+
+			ext.remove(c)
+		}
+
+		if len(ext.elements()) == 0 {
+			n.remove(ext)
+		}
+	}
+}
+
+// addShape adds a DMNDI shape for an element from its biodi bounds.
+func (this *converter) addShape(n, bounds *xmlNode) {
+
+	ref := n.attr(`id`)
+
+	if ref == `` {
+		return
+	}
+
+	b := &xmlNode{name: xml.Name{Space: `dc`, Local: `Bounds`}}
+
+	for _, name := range []string{`height`, `width`, `x`, `y`} {
+		if v := bounds.attr(name); v != `` {
+			b.setAttr(name, v)
+		}
+	}
+
+	shape := &xmlNode{name: xml.Name{Space: `dmndi`, Local: `DMNShape`}}
+	shape.setAttr(`id`, this.id(`DMNShape_` + ref, `DMNShape`))
+	shape.setAttr(`dmnElementRef`, ref)
+	shape.children = append(shape.children, b)
+
+	this.shapes = append(this.shapes, shape)
+}
+
+// addEdge adds a DMNDI edge for a requirement of an element from a biodi
+// edge, which names the source of the requirement. Edges of associations
+// refer to the association itself; other edges that match no requirement
+// are dropped.
+func (this *converter) addEdge(n, edge *xmlNode) {
+
+	var ref string
+	source := edge.attr(`source`)
+
+	for _, req := range n.elements() {
+
+		if !requirementTags[req.name.Local] {
+			continue
+		}
+
+		for _, href := range req.elements() {
+			if (&ElementRef{Href: href.attr(`href`)}).Id() == source {
+				ref = req.attr(`id`)
+			}
+		}
+	}
+
+	if ref == `` && n.name.Local == `association` {
+		ref = n.attr(`id`)
+	}
+
+	if ref == `` {
+		return
+	}
+
+	e := &xmlNode{name: xml.Name{Space: `dmndi`, Local: `DMNEdge`}}
+	e.setAttr(`id`, this.id(`DMNEdge_` + ref, `DMNEdge`))
+	e.setAttr(`dmnElementRef`, ref)
+
+	for _, wp := range edge.elements() {
+		if wp.name.Local == `waypoints` {
+			p := &xmlNode{name: xml.Name{Space: `di`, Local: `waypoint`}}
+			p.setAttr(`x`, wp.attr(`x`))
+			p.setAttr(`y`, wp.attr(`y`))
+			e.children = append(e.children, p)
+		}
+	}
+
+	this.edges = append(this.edges, e)
+}
+
+// addDiagram adds a DMNDI diagram with the collected shapes and edges to
+// the definitions element and declares the namespaces it uses.
+func (this *converter) addDiagram(root *xmlNode) (error) {
+
+	if len(this.shapes) == 0 && len(this.edges) == 0 {
+		return nil
+	}
+
+	for _, decl := range [][2]string{{`dmndi`, NsDmndi13}, {`dc`, NsDc}, {`di`, NsDi}} {
+		if prefix, ns := decl[0], decl[1]; root.namespace(prefix) == `` {
+			root.attrs = append(root.attrs, xml.Attr{Name: xml.Name{Space: `xmlns`, Local: prefix}, Value: ns})
+		} else if bound := root.namespace(prefix); bound != ns {
+			return fmt.Errorf(`prefix %s is bound to %s, not %s`, prefix, bound, ns)
+		}
+	}
+
+	diagram := &xmlNode{name: xml.Name{Space: `dmndi`, Local: `DMNDiagram`}}
+	diagram.setAttr(`id`, this.id(``, `DMNDiagram`))
+
+	for _, c := range this.shapes {
+		diagram.children = append(diagram.children, c)
+	}
+
+	for _, c := range this.edges {
+		diagram.children = append(diagram.children, c)
+	}
+
+	dmndi := &xmlNode{
+		name: xml.Name{Space: `dmndi`, Local: `DMNDI`},
+		children: []interface{}{diagram},
+	}
+
+	dmndi.indent(1)
+
+	// Keep the whitespace before the end tag of the definitions element.
+
+	var tail []interface{}
+
+	if last := len(root.children) - 1; last >= 0 {
+		if isSpace(root.children[last]) {
+			root.children, tail = root.children[:last], []interface{}{root.children[last]}
+		}
+	}
+
+	if tail == nil {
+		tail = []interface{}{xml.CharData("\n")}
+	}
+
+	root.children = append(root.children, xml.CharData("\n  "), dmndi)
+	root.children = append(root.children, tail...)
+
+	return nil
+}
+
+// isXmlnsAttr reports whether an attribute declares a namespace.
+func isXmlnsAttr(a xml.Attr) (bool) {
+	return a.Name.Space == `xmlns` || (a.Name.Space == `` && a.Name.Local == `xmlns`)
+}
+
+// ------------------------------------------------------------------------
+// xmlNode.
+// ------------------------------------------------------------------------
+
+// xmlNode is an element of an XML document parsed without namespace
+// resolution, so that the document can be written back with its prefixes
+// and namespace declarations as they were. The Space of names holds the
+// prefix. Children are *xmlNode, xmlText, xml.CharData, xml.Comment,
+// xml.ProcInst and xml.Directive values. Open records that an element
+// without children was written with an end tag rather than as an
+// empty-element tag.
+type xmlNode struct {
+	name			xml.Name
+	attrs			[]xml.Attr
+	children		[]interface{}
+	open			bool
+}
+
+// xmlText is character data as it was written in the source document,
+// with its entity references and CDATA sections. Character data added by
+// the converter is xml.CharData.
+type xmlText []byte
+
+// parseXmlTree parses an XML document into the tokens before the root
+// element and the root element.
+func parseXmlTree(b []byte) (prolog []interface{}, root *xmlNode, err error) {
+
+	var stack []*xmlNode
+
+	d := xml.NewDecoder(bytes.NewReader(b))
+
+	for {
+
+		start := d.InputOffset()
+		tok, err := d.RawToken()
+
+		if err == io.EOF {
+			break
+		} else if err != nil {
+			return nil, nil, err
+		}
+
+		switch t := tok.(type) {
+
+		case xml.StartElement:
+
+			n := &xmlNode{name: t.Name, attrs: append([]xml.Attr(nil), t.Attr...)}
+			n.open = !bytes.HasSuffix(b[:d.InputOffset()], []byte(`/>`))
+
+			if len(stack) > 0 {
+				top := stack[len(stack)-1]
+				top.children = append(top.children, n)
+			} else if root == nil {
+				root = n
+			} else {
+				return nil, nil, fmt.Errorf(`more than one root element`)
+			}
+
+			stack = append(stack, n)
+
+		case xml.EndElement:
+
+			if len(stack) == 0 {
+				return nil, nil, fmt.Errorf(`unexpected end element %s`, t.Name.Local)
+			}
+
+			stack = stack[:len(stack)-1]
+
+		default:
+
+			if _, ok := tok.(xml.CharData); ok {
+				tok = xmlText(b[start:d.InputOffset()])
+			} else {
+				tok = xml.CopyToken(tok)
+			}
+
+			if len(stack) > 0 {
+				top := stack[len(stack)-1]
+				top.children = append(top.children, tok)
+			} else if root == nil {
+				prolog = append(prolog, tok)
+			}
+		}
+	}
+
+	if root == nil {
+		return nil, nil, fmt.Errorf(`no root element`)
+	} else if len(stack) > 0 {
+		return nil, nil, fmt.Errorf(`unclosed element %s`, stack[len(stack)-1].name.Local)
+	}
+
+	return prolog, root, nil
+}
+
+// attr returns the value of the unprefixed attribute with the given name.
+func (this *xmlNode) attr(name string) (string) {
+
+	for _, a := range this.attrs {
+		if a.Name.Space == `` && a.Name.Local == name {
+			return a.Value
+		}
+	}
+
+	return ``
+}
+
+// setAttr sets the value of an unprefixed attribute.
+func (this *xmlNode) setAttr(name, value string) {
+
+	for i, a := range this.attrs {
+		if a.Name.Space == `` && a.Name.Local == name {
+			this.attrs[i].Value = value
+			return
+		}
+	}
+
+	this.attrs = append(this.attrs, xml.Attr{Name: xml.Name{Local: name}, Value: value})
+}
+
+// namespace returns the namespace a prefix is bound to on the element,
+// or the default namespace for an empty prefix.
+func (this *xmlNode) namespace(prefix string) (string) {
+
+	for _, a := range this.attrs {
+		if prefix == `` && a.Name.Space == `` && a.Name.Local == `xmlns` {
+			return a.Value
+		} else if prefix != `` && a.Name.Space == `xmlns` && a.Name.Local == prefix {
+			return a.Value
+		}
+	}
+
+	return ``
+}
+
+// elements returns the child elements.
+func (this *xmlNode) elements() (nodes []*xmlNode) {
+
+	for _, c := range this.children {
+		if n, ok := c.(*xmlNode); ok {
+			nodes = append(nodes, n)
+		}
+	}
+
+	return nodes
+}
+
+// each calls a function for the element and all its descendants.
+func (this *xmlNode) each(f func(*xmlNode)) {
+
+	f(this)
+
+	for _, c := range this.elements() {
+		c.each(f)
+	}
+}
+
+// remove removes a child element and the whitespace before it. An element
+// left with only whitespace is emptied.
+func (this *xmlNode) remove(n *xmlNode) {
+
+	for i, c := range this.children {
+
+		if c != n {
+			continue
+		}
+
+		j := i
+
+		if i > 0 {
+			if isSpace(this.children[i-1]) {
+				j = i - 1
+			}
+		}
+
+		this.children = append(this.children[:j], this.children[i+1:]...)
+
+		for _, c := range this.children {
+			if !isSpace(c) {
+				return
+			}
+		}
+
+		this.children, this.open = nil, false
+
+		return
+	}
+}
+
+// indent lays out the descendants of an element created by the converter,
+// one element per line, for an element at the given depth.
+func (this *xmlNode) indent(depth int) {
+
+	nodes := this.elements()
+
+	if len(nodes) == 0 {
+		return
+	}
+
+	this.children = nil
+
+	for _, n := range nodes {
+		n.indent(depth + 1)
+		this.children = append(this.children, xml.CharData("\n" + strings.Repeat(`  `, depth + 1)), n)
+	}
+
+	this.children = append(this.children, xml.CharData("\n" + strings.Repeat(`  `, depth)))
+}
+
+// write writes the element and its children as XML.
+func (this *xmlNode) write(w *bytes.Buffer) {
+
+	w.WriteString(`<` + qname(this.name))
+
+	for _, a := range this.attrs {
+		w.WriteString(` ` + qname(a.Name) + `="` + attrEscaper.Replace(a.Value) + `"`)
+	}
+
+	if len(this.children) == 0 && !this.open {
+		w.WriteString(` />`)
+		return
+	}
+
+	w.WriteString(`>`)
+
+	for _, c := range this.children {
+		if n, ok := c.(*xmlNode); ok {
+			n.write(w)
+		} else {
+			writeXmlToken(w, c)
+		}
+	}
+
+	w.WriteString(`</` + qname(this.name) + `>`)
+}
+
+// attrEscaper escapes attribute values.
+var attrEscaper = strings.NewReplacer(
+	`&`, `&amp;`, `<`, `&lt;`, `>`, `&gt;`, `"`, `&quot;`,
+	"\t", `&#x9;`, "\n", `&#xA;`, "\r", `&#xD;`,
+)
+
+// textEscaper escapes character data.
+var textEscaper = strings.NewReplacer(`&`, `&amp;`, `<`, `&lt;`, `>`, `&gt;`)
+
+// isSpace reports whether a child of an element is whitespace.
+func isSpace(tok interface{}) (bool) {
+
+	switch t := tok.(type) {
+	case xmlText:
+		return len(bytes.TrimSpace(t)) == 0
+	case xml.CharData:
+		return len(bytes.TrimSpace(t)) == 0
+	}
+
+	return false
+}
+
+// writeXmlToken writes a token other than an element. Character data from
+// the source document is written as it was.
+func writeXmlToken(w *bytes.Buffer, tok interface{}) {
+
+	switch t := tok.(type) {
+
+	case xmlText:
+		w.Write(t)
+
+	case xml.CharData:
+		w.WriteString(textEscaper.Replace(string(t)))
+
+	case xml.Comment:
+		w.WriteString(`<!--` + string(t) + `-->`)
+
+	case xml.ProcInst:
+		w.WriteString(`<?` + t.Target)
+		if len(t.Inst) > 0 {
+			w.WriteString(` ` + string(t.Inst))
+		}
+		w.WriteString(`?>`)
+
+	case xml.Directive:
+		w.WriteString(`<!` + string(t) + `>`)
+	}
+}
+
+// qname returns a name with its prefix.
+func qname(name xml.Name) (string) {
+	if name.Space != `` {
+		return name.Space + `:` + name.Local
+	}
+	return name.Local
+}
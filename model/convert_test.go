@@ -0,0 +1,222 @@
+package model
+
+import (
+	`bytes`
+	`encoding/xml`
+	`io/ioutil`
+	`strings`
+	`testing`
+)
+
+const testDrd11 = `<?xml version="1.0" encoding="UTF-8"?>
+<!-- dish DRD -->
+<definitions xmlns="http://www.omg.org/spec/DMN/20151101/dmn.xsd" xmlns:biodi="http://bpmn.io/schema/dmn/biodi/1.0" xmlns:camunda="http://camunda.org/schema/1.0/dmn" id="drd" name="Dish DRD" namespace="http://camunda.org/schema/1.0/dmn" expressionLanguage="http://www.omg.org/spec/FEEL/20140401">
+  <decision id="dish" name="Dish" camunda:historyTimeToLive="30">
+    <extensionElements>
+      <biodi:bounds x="150" y="80" width="180" height="80" />
+      <biodi:edge source="season">
+        <biodi:waypoints x="250" y="200" />
+        <biodi:waypoints x="240" y="160" />
+      </biodi:edge>
+      <biodi:edge source="guests">
+        <biodi:waypoints x="400" y="200" />
+        <biodi:waypoints x="300" y="160" />
+      </biodi:edge>
+    </extensionElements>
+    <informationRequirement>
+      <requiredDecision href="#season" />
+    </informationRequirement>
+    <informationRequirement>
+      <requiredInput href="#guests" />
+    </informationRequirement>
+    <decisionTable id="dtDish" hitPolicy="UNIQUE">
+      <input id="in1" label="Season">
+        <inputExpression id="ie1" typeRef="feel:string"><text>season</text></inputExpression>
+      </input>
+      <input id="in2" label="Guests">
+        <inputExpression id="ie2" typeRef="integer"><text>guests</text></inputExpression>
+      </input>
+      <output id="out1" label="Dish" name="dish" typeRef="string" />
+      <rule id="r1">
+        <inputEntry id="e11"><text>"Winter"</text></inputEntry>
+        <inputEntry id="e12"><text>&lt;= 8</text></inputEntry>
+        <outputEntry id="o11"><text><![CDATA["Roastbeef"]]></text></outputEntry>
+      </rule>
+    </decisionTable>
+  </decision>
+  <decision id="season" name="Season">
+    <extensionElements>
+      <biodi:bounds x="150" y="250" width="180" height="80" />
+    </extensionElements>
+    <decisionTable id="dtSeason" hitPolicy="FIRST">
+      <input id="in3" label="Month">
+        <inputExpression id="ie3" typeRef="integer"><text>month</text></inputExpression>
+      </input>
+      <output id="out3" label="Season" name="season" typeRef="string" />
+      <rule id="r3">
+        <inputEntry id="e31"><text>[3..8]</text></inputEntry>
+        <outputEntry id="o31"><text>"Summer"</text></outputEntry>
+      </rule>
+    </decisionTable>
+  </decision>
+  <inputData id="guests" name="Guests">
+    <extensionElements>
+      <biodi:bounds x="400" y="250" width="125" height="45" />
+    </extensionElements>
+  </inputData>
+</definitions>
+`
+
+// testDmndi is the DMNDI part of a DMN 1.3 document.
+type testDmndi struct {
+	Shapes	[]struct {
+		Id	string	`xml:"id,attr"`
+		Ref	string	`xml:"dmnElementRef,attr"`
+		Bounds	struct {
+			X	string	`xml:"x,attr"`
+			Y	string	`xml:"y,attr"`
+			Width	string	`xml:"width,attr"`
+			Height	string	`xml:"height,attr"`
+		}	`xml:"http://www.omg.org/spec/DMN/20180521/DC/ Bounds"`
+	}	`xml:"https://www.omg.org/spec/DMN/20191111/DMNDI/ DMNDI>DMNDiagram>DMNShape"`
+	Edges	[]struct {
+		Id		string	`xml:"id,attr"`
+		Ref		string	`xml:"dmnElementRef,attr"`
+		Waypoints	[]struct {
+			X	string	`xml:"x,attr"`
+			Y	string	`xml:"y,attr"`
+		}	`xml:"http://www.omg.org/spec/DMN/20180521/DI/ waypoint"`
+	}	`xml:"https://www.omg.org/spec/DMN/20191111/DMNDI/ DMNDI>DMNDiagram>DMNEdge"`
+}
+
+func TestUpgradeDmnDoc(t *testing.T) {
+
+	in, err := ioutil.ReadFile(`../doc/dmn.xml`)
+
+	if err != nil {
+		t.Fatal(err)
+	}
+
+	out, err := UpgradeDmn(bytes.NewReader(in))
+
+	if err != nil {
+		t.Fatalf(`UpgradeDmn: %v`, err)
+	}
+
+	dmn, err := NewDmn(bytes.NewReader(out))
+
+	if err != nil {
+		t.Fatalf(`NewDmn: %v`, err)
+	}
+
+	if version, err := dmn.Version(); version != DmnVersion13 || err != nil {
+		t.Errorf(`Version() = %s, %v; want %s`, version, err, DmnVersion13)
+	}
+
+	if _, err := dmn.Decision(`mi9-user-provisioning-rules-roles`); err != nil {
+		t.Errorf(`Decision: %v`, err)
+	}
+
+	// Only the namespace changes; text, including CDATA sections and
+	// entity references, is kept as written.
+
+	end := []byte(`</definitions>`)
+	want := bytes.Replace(in[:bytes.Index(in, end) + len(end)], []byte(NsDmn11), []byte(NsDmn13), 1)
+
+	if got := bytes.TrimSpace(out); !bytes.Equal(got, want) {
+		t.Errorf(`UpgradeDmn changed more than the namespace:\n%s`, got)
+	}
+}
+
+func TestUpgradeDmnDrd(t *testing.T) {
+
+	out, err := UpgradeDmn(testDrd11)
+
+	if err != nil {
+		t.Fatalf(`UpgradeDmn: %v`, err)
+	}
+
+	dmn, err := NewDmn(bytes.NewReader(out))
+
+	if err != nil {
+		t.Fatalf(`NewDmn: %v`, err)
+	}
+
+	if version, err := dmn.Version(); version != DmnVersion13 || err != nil {
+		t.Errorf(`Version() = %s, %v; want %s`, version, err, DmnVersion13)
+	}
+
+	s := string(out)
+
+	for _, tt := range []struct {
+		text	string
+		want	bool
+	}{
+		{`<!-- dish DRD -->`,						true},
+		{`expressionLanguage="` + NsFeel13 + `"`,			true},
+		{`typeRef="string"><text>season</text>`,			true},
+		{`<text>&lt;= 8</text>`,					true},
+		{`<text><![CDATA["Roastbeef"]]></text>`,			true},
+		{`<informationRequirement id="InformationRequirement_1">`,	true},
+		{`<informationRequirement id="InformationRequirement_2">`,	true},
+		{`feel:`,							false},
+		{`biodi`,							false},
+		{`extensionElements`,						false},
+	} {
+		if got := strings.Contains(s, tt.text); got != tt.want {
+			t.Errorf(`output contains %s = %v; want %v`, tt.text, got, tt.want)
+		}
+	}
+
+	var di testDmndi
+
+	if err := xml.Unmarshal(out, &di); err != nil {
+		t.Fatalf(`xml.Unmarshal: %v`, err)
+	}
+
+	shapes := []string{
+		`DMNShape_dish dish 150 80 180 80`,
+		`DMNShape_season season 150 250 180 80`,
+		`DMNShape_guests guests 400 250 125 45`,
+	}
+
+	if len(di.Shapes) != len(shapes) {
+		t.Fatalf(`%d shapes; want %d`, len(di.Shapes), len(shapes))
+	}
+
+	for i, s := range di.Shapes {
+		b := s.Bounds
+		if got := strings.Join([]string{s.Id, s.Ref, b.X, b.Y, b.Width, b.Height}, ` `); got != shapes[i] {
+			t.Errorf(`shape %d = %s; want %s`, i, got, shapes[i])
+		}
+	}
+
+	edges := []string{
+		`DMNEdge_InformationRequirement_1 InformationRequirement_1 250,200 240,160`,
+		`DMNEdge_InformationRequirement_2 InformationRequirement_2 400,200 300,160`,
+	}
+
+	if len(di.Edges) != len(edges) {
+		t.Fatalf(`%d edges; want %d`, len(di.Edges), len(edges))
+	}
+
+	for i, e := range di.Edges {
+		fields := []string{e.Id, e.Ref}
+		for _, wp := range e.Waypoints {
+			fields = append(fields, wp.X + `,` + wp.Y)
+		}
+		if got := strings.Join(fields, ` `); got != edges[i] {
+			t.Errorf(`edge %d = %s; want %s`, i, got, edges[i])
+		}
+	}
+
+	// DMN 1.3 is returned unchanged, so upgrading again is a no-op.
+
+	again, err := UpgradeDmn(bytes.NewReader(out))
+
+	if err != nil {
+		t.Fatalf(`UpgradeDmn(DMN 1.3): %v`, err)
+	} else if !bytes.Equal(again, out) {
+		t.Errorf(`UpgradeDmn(DMN 1.3) changed the document:\n%s`, again)
+	}
+}
@@ -88,7 +88,8 @@ type DmnElements map[DmnElement]int
 // DmnElements Methods.
 // ------------------------------------------------------------------------
 
-// NewDmnElements creates a collection of DmnElement objects from a DMN.
+// NewDmnElements creates a collection of DmnElement objects from a DMN,
+// covering all of its decisions, or from a single Decision.
 func NewDmnElements(t interface{}) (DmnElements, error) {
 
 	this := make(DmnElements)
@@ -134,64 +135,80 @@ func (this DmnElements) Load(t interface{}) (error) {
 // load imports a DMN into a data structure that allows comparison
 // of the DMN elements of two different objects.
 func (this DmnElements) load(t interface{}, cval int) (error) {
+	return this.walk(t, ``, cval)
+}
 
-	var tag, id string
+// walk imports an element and its children. Elements without an id, such
+// as requirements, are identified by the id of their parent element and
+// their tag; only the root element must have an id.
+func (this DmnElements) walk(t interface{}, parent string, cval int) (error) {
 
 	switch obj := t.(type) {
 
-	case *Dmn:
+	case nil:
+
+		return nil
+
+	case *Dmn, *Decision:
 
 		if em, err := toMap(t); err != nil {
 			return err
 		} else {
-			return this.load(em, cval)
+			return this.walk(em, parent, cval)
 		}
 
 	case map[string]interface{}:
 
-		tm := make(map[string]interface{})
+		var tag, id string
+
+		if xn, ok := obj[`xmlName`].(map[string]interface{}); ok {
+			tag, _ = xn[`Local`].(string)
+		}
+
+		if id, _ = obj[`id`].(string); id == `` && parent != `` && tag != `` {
+			id = parent + `/` + tag
+		}
+
+		if tag == `` || id == `` {
+			return fmt.Errorf(`missing Tag and Id`)
+		}
 
 		for name, value := range obj {
 
-			switch obj := value.(type) {
+			switch value := value.(type) {
 
 			case string:
 
-				if name == `id` {
-					id = obj
-				} else {
-					tm[name] = value
+				if name != `id` {
+					el := DmnElement{tag, id, name, value}
+					this[el] += cval
 				}
 
+			case float64, bool:
+
+				el := DmnElement{tag, id, name, fmt.Sprint(value)}
+				this[el] += cval
+
 			case map[string]interface{}:
 
 				if name == `xmlName` {
-					tag = obj[`Local`].(string)
-				} else if err := this.load(obj, cval); err != nil {
+					continue
+				} else if err := this.walk(value, id, cval); err != nil {
 					return err
 				}
 
 			default:
 
-				if err := this.load(obj, cval); err != nil {
+				if err := this.walk(value, id, cval); err != nil {
 					return err
 				}
 			}
 		}
 
-		if tag == `` || id == `` {
-			return fmt.Errorf(`missing Tag and Id`)
-		}
-
-		for name, value := range tm {
-			el := DmnElement{tag, id, name, value.(string)}
-			this[el] += cval
-		}
-
 	case []interface{}:
 
 		for _, value := range obj {
-			if err := this.load(value, cval); err != nil {
+			if err := this.walk(value, parent, cval); err != nil {
 				return err
 			}
 		}
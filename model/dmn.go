@@ -14,7 +14,13 @@
 
 package model
 
-import "encoding/xml"
+import (
+	`encoding/json`
+	`encoding/xml`
+	`fmt`
+	`strconv`
+	`strings`
+)
 
 // ==============================================================================
 // See https://docs.camunda.org/manual/7.4/reference/dmn11/decision-table/
@@ -24,15 +30,19 @@ import "encoding/xml"
 // Dmn.
 // ------------------------------------------------------------------------
 
-// Dmn contains Decision Model and Notation definitions defining the 
-// Decision and DecisionTable.
+// Dmn contains Decision Model and Notation definitions defining one or
+// more Decisions and their DecisionTables. Camunda deploys each decision
+// as a decision definition whose key is the decision id.
 type Dmn struct {
 	XMLName           xml.Name            `json:"xmlName"`
 	Xmlns             string              `xml:"xmlns,attr" json:"xmlns"`
 	Id                string              `xml:"id,attr" json:"id"`
 	Name              string              `xml:"name,attr" json:"name"`
 	Namespace         string              `xml:"namespace,attr" json:"namespace"`
-	Decision          *Decision           `xml:"decision,child" json:"decision"`
+	Decisions         []*Decision         `xml:"decision,child" json:"decisions"`
+	InputData         []*InputData        `xml:"inputData,child" json:"inputData,omitempty"`
+	KnowledgeSources  []*KnowledgeSource  `xml:"knowledgeSource,child" json:"knowledgeSource,omitempty"`
+	BusinessKnowledgeModels []*BusinessKnowledgeModel `xml:"businessKnowledgeModel,child" json:"businessKnowledgeModel,omitempty"`
 }
 
 // A DecisionTable is decision logic which can be depicted as a table in
@@ -44,22 +54,214 @@ type Dmn struct {
 // element. The id is the technical identifier of the decision. It is
 // set in the id attribute on the decision element.
 
+// The requirements of a decision are the other elements of the decision
+// requirements graph it depends on. An information requirement names a
+// decision or input data whose value the decision uses, a knowledge
+// requirement names a business knowledge model it invokes, and an
+// authority requirement names a knowledge source that governs it.
+
+// The Camunda extension attributes camunda:versionTag and
+// camunda:historyTimeToLive on the decision element set the version tag
+// and the history time to live, in days, of the decision definition the
+// engine deploys for the decision. The history time to live is kept as
+// written, since the Modeler writes an empty value when it is cleared and
+// the value may be a placeholder resolved at deployment; HistoryTtl parses
+// it.
+
 type Decision struct {
 	XMLName           xml.Name            `json:"xmlName"`
 	Id                string              `xml:"id,attr" json:"id"`
 	Name              string              `xml:"name,attr" json:"name"`
+	VersionTag        string              `xml:"http://camunda.org/schema/1.0/dmn versionTag,attr" json:"versionTag,omitempty"`
+	HistoryTimeToLive string              `xml:"http://camunda.org/schema/1.0/dmn historyTimeToLive,attr" json:"historyTimeToLive,omitempty"`
+	Description       string              `xml:"description" json:"description,omitempty"`
+	InformationRequirements []*InformationRequirement `xml:"informationRequirement,child" json:"informationRequirement,omitempty"`
+	KnowledgeRequirements []*KnowledgeRequirement `xml:"knowledgeRequirement,child" json:"knowledgeRequirement,omitempty"`
+	AuthorityRequirements []*AuthorityRequirement `xml:"authorityRequirement,child" json:"authorityRequirement,omitempty"`
 	DecisionTable     *DecisionTable      `xml:"decisionTable,child" json:"decisionTable"`
+	Variable          *InformationItem    `xml:"variable,child" json:"variable,omitempty"`
+	LiteralExpression *LiteralExpression  `xml:"literalExpression,child" json:"literalExpression,omitempty"`
+	Context           *Context            `xml:"context,child" json:"context,omitempty"`
+	Invocation        *Invocation         `xml:"invocation,child" json:"invocation,omitempty"`
+	Relation          *Relation           `xml:"relation,child" json:"relation,omitempty"`
+}
+
+// Input data denotes information used as an input by decisions, such as
+// a process variable. It is represented by an inputData element inside
+// the definitions element.
+
+type InputData struct {
+	XMLName           xml.Name            `json:"xmlName"`
+	Id                string              `xml:"id,attr" json:"id"`
+	Name              string              `xml:"name,attr" json:"name"`
+}
+
+// A knowledge source denotes an authority for a decision or business
+// knowledge model, such as a policy or regulation. It is represented by
+// a knowledgeSource element inside the definitions element.
+
+type KnowledgeSource struct {
+	XMLName           xml.Name            `json:"xmlName"`
+	Id                string              `xml:"id,attr" json:"id"`
+	Name              string              `xml:"name,attr" json:"name"`
+	AuthorityRequirements []*AuthorityRequirement `xml:"authorityRequirement,child" json:"authorityRequirement,omitempty"`
+}
+
+// A business knowledge model denotes reusable decision logic invoked by
+// decisions. It is represented by a businessKnowledgeModel element inside
+// the definitions element.
+
+type BusinessKnowledgeModel struct {
+	XMLName           xml.Name            `json:"xmlName"`
+	Id                string              `xml:"id,attr" json:"id"`
+	Name              string              `xml:"name,attr" json:"name"`
+	KnowledgeRequirements []*KnowledgeRequirement `xml:"knowledgeRequirement,child" json:"knowledgeRequirement,omitempty"`
+	AuthorityRequirements []*AuthorityRequirement `xml:"authorityRequirement,child" json:"authorityRequirement,omitempty"`
+}
+
+// Requirements reference the required element with an href attribute of
+// the form #<id> on a child element named for the kind of element
+// required. Since DMN 1.3 requirements also have an id.
+
+type InformationRequirement struct {
+	XMLName           xml.Name            `json:"xmlName"`
+	Id                string              `xml:"id,attr" json:"id,omitempty"`
+	RequiredDecision  *ElementRef         `xml:"requiredDecision,child" json:"requiredDecision,omitempty"`
+	RequiredInput     *ElementRef         `xml:"requiredInput,child" json:"requiredInput,omitempty"`
+}
+
+type KnowledgeRequirement struct {
+	XMLName           xml.Name            `json:"xmlName"`
+	Id                string              `xml:"id,attr" json:"id,omitempty"`
+	RequiredKnowledge *ElementRef         `xml:"requiredKnowledge,child" json:"requiredKnowledge,omitempty"`
+}
+
+type AuthorityRequirement struct {
+	XMLName           xml.Name            `json:"xmlName"`
+	Id                string              `xml:"id,attr" json:"id,omitempty"`
+	RequiredDecision  *ElementRef         `xml:"requiredDecision,child" json:"requiredDecision,omitempty"`
+	RequiredInput     *ElementRef         `xml:"requiredInput,child" json:"requiredInput,omitempty"`
+	RequiredAuthority *ElementRef         `xml:"requiredAuthority,child" json:"requiredAuthority,omitempty"`
+}
+
+type ElementRef struct {
+	XMLName           xml.Name            `json:"xmlName"`
+	Href              string              `xml:"href,attr" json:"href"`
+}
+
+// The decision logic of a decision is a decision table or one of the other
+// boxed expressions of DMN: a literal expression, a context, an invocation
+// or a relation. The variable of a decision names and types the result of
+// its decision logic.
+
+// A literal expression is an expression which will be evaluated by the DMN
+// engine. The expression is set inside a text element that is a child of
+// the literalExpression XML element. The expression language and the type
+// of its result can be set by the expressionLanguage and typeRef attributes
+// on the literalExpression XML element.
+
+type LiteralExpression struct {
+	XMLName           xml.Name            `json:"xmlName"`
+	Id                string              `xml:"id,attr" json:"id"`
+	ExpressionLanguage string             `xml:"expressionLanguage,attr" json:"expressionLanguage,omitempty"`
+	TypeRef           string              `xml:"typeRef,attr" json:"typeRef,omitempty"`
+	Text              string              `xml:"text" json:"text"`
+}
+
+// An information item names and types a value, such as the result of a
+// decision, a context entry, an invocation parameter or a relation column.
+
+type InformationItem struct {
+	XMLName           xml.Name            `json:"xmlName"`
+	Id                string              `xml:"id,attr" json:"id"`
+	Name              string              `xml:"name,attr" json:"name"`
+	TypeRef           string              `xml:"typeRef,attr" json:"typeRef,omitempty"`
+}
+
+// A context is a list of context entries, each binding the value of an
+// expression to a variable. A context entry without a variable is the
+// result of the context.
+
+type Context struct {
+	XMLName           xml.Name            `json:"xmlName"`
+	Id                string              `xml:"id,attr" json:"id"`
+	TypeRef           string              `xml:"typeRef,attr" json:"typeRef,omitempty"`
+	ContextEntries    []*ContextEntry     `xml:"contextEntry,child" json:"contextEntry"`
+}
+
+type ContextEntry struct {
+	XMLName           xml.Name            `json:"xmlName"`
+	Variable          *InformationItem    `xml:"variable,child" json:"variable,omitempty"`
+	LiteralExpression *LiteralExpression  `xml:"literalExpression,child" json:"literalExpression,omitempty"`
+	Context           *Context            `xml:"context,child" json:"context,omitempty"`
+	Invocation        *Invocation         `xml:"invocation,child" json:"invocation,omitempty"`
+	Relation          *Relation           `xml:"relation,child" json:"relation,omitempty"`
+	DecisionTable     *DecisionTable      `xml:"decisionTable,child" json:"decisionTable,omitempty"`
+}
+
+// An invocation calls a function, usually a business knowledge model named
+// by its literal expression, binding the value of an expression to each of
+// its parameters.
+
+type Invocation struct {
+	XMLName           xml.Name            `json:"xmlName"`
+	Id                string              `xml:"id,attr" json:"id"`
+	TypeRef           string              `xml:"typeRef,attr" json:"typeRef,omitempty"`
+	LiteralExpression *LiteralExpression  `xml:"literalExpression,child" json:"literalExpression,omitempty"`
+	Bindings          []*Binding          `xml:"binding,child" json:"binding"`
+}
+
+type Binding struct {
+	XMLName           xml.Name            `json:"xmlName"`
+	Parameter         *InformationItem    `xml:"parameter,child" json:"parameter,omitempty"`
+	LiteralExpression *LiteralExpression  `xml:"literalExpression,child" json:"literalExpression,omitempty"`
+	Context           *Context            `xml:"context,child" json:"context,omitempty"`
+	Invocation        *Invocation         `xml:"invocation,child" json:"invocation,omitempty"`
+	Relation          *Relation           `xml:"relation,child" json:"relation,omitempty"`
+	DecisionTable     *DecisionTable      `xml:"decisionTable,child" json:"decisionTable,omitempty"`
+}
+
+// A relation is a table of literal expressions: a list of columns and a
+// list of rows, each row holding one expression per column.
+
+type Relation struct {
+	XMLName           xml.Name            `json:"xmlName"`
+	Id                string              `xml:"id,attr" json:"id"`
+	TypeRef           string              `xml:"typeRef,attr" json:"typeRef,omitempty"`
+	Columns           []*InformationItem  `xml:"column,child" json:"column"`
+	Rows              []*RelationRow      `xml:"row,child" json:"row"`
+}
+
+type RelationRow struct {
+	XMLName           xml.Name            `json:"xmlName"`
+	Id                string              `xml:"id,attr" json:"id"`
+	LiteralExpressions []*LiteralExpression `xml:"literalExpression,child" json:"literalExpression"`
 }
 
 type DecisionTable struct {
 	XMLName           xml.Name            `json:"xmlName"`
 	Id                string              `xml:"id,attr" json:"id"`
 	HitPolicy         string              `xml:"hitPolicy,attr" json:"hitPolicy"`
+	Aggregation       string              `xml:"aggregation,attr" json:"aggregation,omitempty"`
+	PreferredOrientation string           `xml:"preferredOrientation,attr" json:"preferredOrientation,omitempty"`
 	Inputs            []*Input            `xml:"input,child" json:"input"`
 	Outputs           []*Output           `xml:"output,child" json:"output"`
+	Annotations       []*RuleAnnotationClause `xml:"annotation,child" json:"annotation,omitempty"`
 	Rules             []*Rule             `xml:"rule,child" json:"rule"`
 }
 
+// The hit policy specifies what the result of the decision table is in
+// case of multiple matching rules. It is set as the hitPolicy attribute on
+// the decisionTable XML element. If no hit policy is set then the default
+// hit policy UNIQUE is used. The COLLECT hit policy may be combined with
+// an aggregator, set as the aggregation attribute, which reduces the
+// output values of all matching rules to a single value.
+
+// The preferred orientation of a decision table, set as the
+// preferredOrientation attribute, tells modelers how to lay the table out:
+// Rule-as-Row (the default), Rule-as-Column or CrossTable. It does not
+// affect evaluation.
+
 // A decision table can have one or more inputs, also called input
 // clauses. An input clause defines the id, label, expression and type
 // of a decision table input. An input clause is represented by an input
@@ -74,11 +276,23 @@ type DecisionTable struct {
 // input XML element in the label attribute. Note that the label is not
 // required but recommended since it helps to understand the decision.
 
+// The input variable is the name of the variable which holds the value of
+// the input expression while the input entries are evaluated. It is set
+// by the Camunda extension attribute camunda:inputVariable on the input
+// XML element. If no input variable is set then cellInput is used.
+
+// The input values of an input clause list the allowed values of the
+// input. The list is set inside a text element that is a child of the
+// inputValues XML element.
+
 type Input struct {
 	XMLName           xml.Name            `json:"xmlName"`
 	Id                string              `xml:"id,attr" json:"id"`
 	Label             string              `xml:"label,attr" json:"label"`
+	InputVariable     string              `xml:"http://camunda.org/schema/1.0/dmn inputVariable,attr" json:"inputVariable,omitempty"`
+	Description       string              `xml:"description" json:"description"`
 	InputExpressions  []*InputExpression  `xml:"inputExpression,child" json:"inputExpression"`
+	InputValues       *UnaryTests         `xml:"inputValues,child" json:"inputValues,omitempty"`
 }
 
 // An input expression specifies how the value of the input clause is
@@ -133,12 +347,31 @@ type InputExpression struct {
 // value into another type. For example, transform the output value 80% of
 // type String into a Double using a custom data type.
 
+// The default output entry of an output clause is the value of the output
+// when no rule matches. It is set inside a text element that is a child of
+// the defaultOutputEntry XML element.
+
 type Output struct {
 	XMLName           xml.Name            `json:"xmlName"`
 	Id                string              `xml:"id,attr" json:"id"`
 	Label             string              `xml:"label,attr" json:"label"`
 	Name              string              `xml:"name,attr" json:"name"`
 	TypeRef           string              `xml:"typeRef,attr" json:"typeRef"`
+	Description       string              `xml:"description" json:"description"`
+	OutputValues      *UnaryTests         `xml:"outputValues,child" json:"outputValues,omitempty"`
+	DefaultOutputEntry *LiteralExpression `xml:"defaultOutputEntry,child" json:"defaultOutputEntry,omitempty"`
+}
+
+// The output values of an output clause list the allowed values of the
+// output in decreasing order of priority. They are used by the PRIORITY
+// and OUTPUT ORDER hit policies to order the results of matching rules.
+// The list is set inside a text element that is a child of the
+// outputValues XML element.
+
+type UnaryTests struct {
+	XMLName           xml.Name            `json:"xmlName"`
+	Id                string              `xml:"id,attr" json:"id"`
+	Text              string              `xml:"text" json:"text"`
 }
 
 // A decision table can have one or more rules. Each rule contains input
@@ -151,8 +384,10 @@ type Output struct {
 type Rule struct {
 	XMLName           xml.Name            `json:"xmlName"`
 	Id                string              `xml:"id,attr" json:"id"`
+	Description       string              `xml:"description" json:"description"`
 	InputEntries      []*InputEntry       `xml:"inputEntry,child" json:"inputEntry"`
 	OutputEntries     []*OutputEntry      `xml:"outputEntry,child" json:"outputEntry"`
+	AnnotationEntries []*AnnotationEntry  `xml:"annotationEntry,child" json:"annotationEntry,omitempty"`
 }
 
 // A rule can have one or more input entries which are the conditions of
@@ -171,6 +406,7 @@ type Rule struct {
 type InputEntry struct {
 	XMLName           xml.Name            `json:"xmlName"`
 	Id                string              `xml:"id,attr" json:"id"`
+	Description       string              `xml:"description" json:"description"`
 	Text               string             `xml:"text" json:"text"`
 }
 
@@ -197,6 +433,24 @@ type OutputEntry struct {
 	Text              string              `xml:"text" json:"text"`
 }
 
+// Since DMN 1.3 a decision table can have annotation columns, each
+// represented by an annotation element with a name attribute inside the
+// decisionTable XML element. A rule holds one annotation entry per
+// annotation column, with the annotation text inside a text element.
+// Modelers that predate annotation columns show the description of the
+// rule as its annotation instead.
+
+type RuleAnnotationClause struct {
+	XMLName           xml.Name            `json:"xmlName"`
+	Name              string              `xml:"name,attr" json:"name"`
+	Description       string              `xml:"description" json:"description"`
+}
+
+type AnnotationEntry struct {
+	XMLName           xml.Name            `json:"xmlName"`
+	Text              string              `xml:"text" json:"text"`
+}
+
 // ------------------------------------------------------------------------
 // Dmn Methods.
 // ------------------------------------------------------------------------
@@ -218,8 +472,218 @@ func (this *Dmn) Json() ([]byte, error) {
 	return toJson(this)
 }
 
-// Rules returns the DecisionTable as a collection of Rules suitable for
-// output to a CSV file.
-func (this *Dmn) Rules() (DmnRules, error) {
+// UnmarshalJSON implements the json.Unmarshaler interface for Dmn. It
+// also accepts the single decision object of JSON written before a Dmn
+// held a list of decisions.
+func (this *Dmn) UnmarshalJSON(b []byte) (error) {
+
+	type dmn Dmn
+
+	var v struct {
+		*dmn
+		Decision          *Decision           `json:"decision"`
+	}
+
+	v.dmn = (*dmn)(this)
+
+	if err := json.Unmarshal(b, &v); err != nil {
+		return err
+	}
+
+	if v.Decision != nil && len(this.Decisions) == 0 {
+		this.Decisions = []*Decision{v.Decision}
+	}
+
+	return nil
+}
+
+// Decision returns the decision with the given id.
+func (this *Dmn) Decision(id string) (*Decision, error) {
+
+	for _, d := range this.Decisions {
+		if d.Id == id {
+			return d, nil
+		}
+	}
+
+	return nil, fmt.Errorf(`dmn %s has no decision %s`, this.Id, id)
+}
+
+// DecisionTables returns the decisions whose logic is a decision table,
+// in document order.
+func (this *Dmn) DecisionTables() (ds []*Decision) {
+
+	for _, d := range this.Decisions {
+		if d.DecisionTable != nil {
+			ds = append(ds, d)
+		}
+	}
+
+	return ds
+}
+
+// Drg returns the decision requirements graph of the Dmn.
+func (this *Dmn) Drg() (*Drg, error) {
+	return NewDrg(this)
+}
+
+// only returns the decision of a Dmn that has exactly one.
+func (this *Dmn) only() (*Decision, error) {
+
+	switch len(this.Decisions) {
+	case 0:
+		return nil, fmt.Errorf(`dmn %s has no decision`, this.Id)
+	case 1:
+		return this.Decisions[0], nil
+	}
+
+	return nil, fmt.Errorf(`dmn %s has %d decisions`, this.Id, len(this.Decisions))
+}
+
+// Rules returns the DecisionTables of the decisions with the given ids, or
+// of all decisions that have one if no ids are given, as collections of
+// Rules suitable for output to a CSV file.
+func (this *Dmn) Rules(ids ...string) ([]DmnRules, error) {
+
+	ds := this.DecisionTables()
+
+	if len(ids) > 0 {
+
+		ds = nil
+
+		for _, id := range ids {
+			if d, err := this.Decision(id); err != nil {
+				return nil, err
+			} else {
+				ds = append(ds, d)
+			}
+		}
+	}
+
+	var rules []DmnRules
+
+	for _, d := range ds {
+		if r, err := d.Rules(); err != nil {
+			return nil, err
+		} else {
+			rules = append(rules, r)
+		}
+	}
+
+	return rules, nil
+}
+
+// ------------------------------------------------------------------------
+// Decision Methods.
+// ------------------------------------------------------------------------
+
+// Logic returns the tag of the decision logic of the Decision, such as
+// decisionTable or literalExpression, or an empty string if it has none.
+func (this *Decision) Logic() (string) {
+
+	switch {
+	case this.DecisionTable != nil:
+		return `decisionTable`
+	case this.LiteralExpression != nil:
+		return `literalExpression`
+	case this.Context != nil:
+		return `context`
+	case this.Invocation != nil:
+		return `invocation`
+	case this.Relation != nil:
+		return `relation`
+	default:
+		return ``
+	}
+}
+
+// Table returns the decision table of the Decision, or an error if its
+// decision logic is not a decision table.
+func (this *Decision) Table() (*DecisionTable, error) {
+
+	if logic := this.Logic(); logic == `` {
+		return nil, fmt.Errorf(`decision %s has no decision logic`, this.Id)
+	} else if logic != `decisionTable` {
+		return nil, fmt.Errorf(`decision %s is not a decision table but %s`, this.Id, logic)
+	} else {
+		return this.DecisionTable, nil
+	}
+}
+
+// RequiredDecisions returns the ids of the decisions whose results the
+// Decision uses, in document order.
+func (this *Decision) RequiredDecisions() (ids []string) {
+
+	for _, ir := range this.InformationRequirements {
+		if ir.RequiredDecision != nil {
+			ids = append(ids, ir.RequiredDecision.Id())
+		}
+	}
+
+	return ids
+}
+
+// HistoryTtl parses the camunda:historyTimeToLive attribute of the
+// Decision, either a number of days or an ISO 8601 duration in days such
+// as P180D. It returns nil if the attribute is absent or empty, and an
+// error if it is not a number of days.
+func (this *Decision) HistoryTtl() (*int, error) {
+
+	s := strings.TrimSpace(this.HistoryTimeToLive)
+
+	if s == `` {
+		return nil, nil
+	} else if strings.HasPrefix(s, `P`) && strings.HasSuffix(s, `D`) {
+		s = s[1:len(s)-1]
+	}
+
+	if n, err := strconv.Atoi(s); err != nil || n < 0 {
+		return nil, fmt.Errorf(`history time to live %q of decision %s is not a number of days`,
+			this.HistoryTimeToLive, this.Id)
+	} else {
+		return &n, nil
+	}
+}
+
+// Rules returns the DecisionTable of the Decision as a collection of Rules
+// suitable for output to a CSV file.
+func (this *Decision) Rules() (DmnRules, error) {
 	return NewDmnRules(this)
 }
+
+// ------------------------------------------------------------------------
+// UnaryTests and LiteralExpression Methods.
+// ------------------------------------------------------------------------
+
+// text returns the expression text of the UnaryTests, or an empty string
+// if there are none.
+func (this *UnaryTests) text() (string) {
+	if this == nil {
+		return ``
+	}
+	return this.Text
+}
+
+// text returns the expression text of the LiteralExpression, or an empty
+// string if there is none.
+func (this *LiteralExpression) text() (string) {
+	if this == nil {
+		return ``
+	}
+	return this.Text
+}
+
+// ------------------------------------------------------------------------
+// ElementRef Methods.
+// ------------------------------------------------------------------------
+
+// Id returns the id of the referenced element. References to elements of
+// other documents, of the form <namespace>#<id>, return the id part.
+func (this *ElementRef) Id() (string) {
+
+	if i := strings.LastIndex(this.Href, `#`); i >= 0 {
+		return this.Href[i+1:]
+	}
+
+	return this.Href
+}
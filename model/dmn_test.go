@@ -0,0 +1,99 @@
+package model
+
+import (
+	`fmt`
+	`testing`
+)
+
+const testTtlDmn = `<?xml version="1.0" encoding="UTF-8"?>
+<definitions xmlns="http://www.omg.org/spec/DMN/20151101/dmn.xsd" xmlns:camunda="http://camunda.org/schema/1.0/dmn" id="definitions" name="definitions" namespace="http://camunda.org/schema/1.0/dmn">
+  <decision id="ttl" name="ttl" %s>
+    <decisionTable id="decisionTable">
+      <input id="input1" label="In">
+        <inputExpression id="inputExpression1" typeRef="string">
+          <text>in</text>
+        </inputExpression>
+      </input>
+      <output id="output1" label="Out" name="out" typeRef="string" />
+    </decisionTable>
+  </decision>
+</definitions>`
+
+func TestDecisionHistoryTtl(t *testing.T) {
+
+	tests := []struct {
+		attr	string
+		want	string
+		err	bool
+	}{
+		{``,						`none`,		false},
+		{`camunda:historyTimeToLive=""`,		`none`,		false},
+		{`camunda:historyTimeToLive=" "`,		`none`,		false},
+		{`camunda:historyTimeToLive="30"`,		`30 days`,	false},
+		{`camunda:historyTimeToLive="P180D"`,		`180 days`,	false},
+		{`camunda:historyTimeToLive="P1Y"`,		`none`,		true},
+		{`camunda:historyTimeToLive="${ttl}"`,		`none`,		true},
+		{`camunda:historyTimeToLive="-1"`,		`none`,		true},
+	}
+
+	for _, tt := range tests {
+
+		dmn, err := NewDmn(fmt.Sprintf(testTtlDmn, tt.attr))
+
+		if err != nil {
+			t.Errorf(`NewDmn(%s): %v`, tt.attr, err)
+			continue
+		}
+
+		ttl, err := dmn.Decisions[0].HistoryTtl()
+
+		if got := ttlString(ttl); got != tt.want || (err != nil) != tt.err {
+			t.Errorf(`HistoryTtl(%s) = %s, %v; want %s, error %v`, tt.attr, got, err, tt.want, tt.err)
+		}
+	}
+}
+
+func TestCheckHistoryTtl(t *testing.T) {
+
+	thirty := 30
+
+	tests := []struct {
+		attr	string
+		engine	*int
+		drift	bool
+	}{
+		{``,						nil,		false},
+		{`camunda:historyTimeToLive=""`,		nil,		false},
+		{`camunda:historyTimeToLive="P30D"`,		&thirty,	false},
+		{`camunda:historyTimeToLive="P30D"`,		nil,		true},
+		{`camunda:historyTimeToLive="31"`,		&thirty,	true},
+		{`camunda:historyTimeToLive="${ttl}"`,		&thirty,	true},
+	}
+
+	for _, tt := range tests {
+
+		dmn, err := NewDmn(fmt.Sprintf(testTtlDmn, tt.attr))
+
+		if err != nil {
+			t.Errorf(`NewDmn(%s): %v`, tt.attr, err)
+			continue
+		}
+
+		di := &DmnInfo{Id: `ttl:1:1`, Key: `ttl`, HistoryTtl: tt.engine}
+
+		err = di.CheckHistoryTtl(dmn)
+
+		if _, ok := err.(*HistoryTtlError); ok != tt.drift || (err != nil) != tt.drift {
+			t.Errorf(`CheckHistoryTtl(%s, %s) = %v, want drift %v`, tt.attr, ttlString(tt.engine), err, tt.drift)
+		}
+	}
+
+	dmn, _ := NewDmn(fmt.Sprintf(testTtlDmn, ``))
+	di := &DmnInfo{Id: `other:1:1`, Key: `other`}
+
+	if err := di.CheckHistoryTtl(dmn); err == nil {
+		t.Errorf(`CheckHistoryTtl of a missing decision succeeded`)
+	} else if _, ok := err.(*HistoryTtlError); ok {
+		t.Errorf(`CheckHistoryTtl of a missing decision = %v, want lookup error`, err)
+	}
+}
@@ -0,0 +1,45 @@
+// Copyright 2018 John Scherff
+//
+// Licensed under the Apache License, version 2.0 (the "License");
+// you may not use this file except in compliance with the License.
+// You may obtain a copy of the License at
+//
+//     http://www.apache.org/licenses/LICENSE-2.0
+//
+// Unless required by applicable law or agreed to in writing, software
+// distributed under the License is distributed on an "AS IS" BASIS,
+// WITHOUT WARRANTIES OR CONDITIONS OF ANY KIND, either express or implied.
+// See the License for the specific language governing permissions and
+// limitations under the License.
+
+package model
+
+// ------------------------------------------------------------------------
+// DmnCount.
+// ------------------------------------------------------------------------
+
+// DmnCount contains the number of DMNs matching a query.
+type DmnCount struct {
+	Count             int                 `json:"count"`
+}
+
+// ------------------------------------------------------------------------
+// DmnCount Methods.
+// ------------------------------------------------------------------------
+
+// NewDmnCount creates and loads a new DmnCount object from a JSON source.
+func NewDmnCount(src interface{}) (*DmnCount, error) {
+	this := new(DmnCount)
+	err := this.Load(src)
+	return this, err
+}
+
+// Load unmarshals JSON from Reader, url, file or string into an object.
+func (this *DmnCount) Load(src interface{}) error {
+	return load(this, src, `json`)
+}
+
+// Json returns the DmnCount object as a JSON byte array.
+func (this *DmnCount) Json() ([]byte, error) {
+	return toJson(this)
+}
@@ -0,0 +1,68 @@
+// Copyright 2018 John Scherff
+//
+// Licensed under the Apache License, version 2.0 (the "License");
+// you may not use this file except in compliance with the License.
+// You may obtain a copy of the License at
+//
+//     http://www.apache.org/licenses/LICENSE-2.0
+//
+// Unless required by applicable law or agreed to in writing, software
+// distributed under the License is distributed on an "AS IS" BASIS,
+// WITHOUT WARRANTIES OR CONDITIONS OF ANY KIND, either express or implied.
+// See the License for the specific language governing permissions and
+// limitations under the License.
+
+package model
+
+import (
+	`mime`
+	`strings`
+)
+
+// ------------------------------------------------------------------------
+// DmnDiagram.
+// ------------------------------------------------------------------------
+
+// DmnDiagram is the diagram image deployed with a DMN Decision Definition,
+// such as a PNG or SVG rendering exported from the modeler.
+type DmnDiagram struct {
+	ContentType       string              `json:"contentType"`
+	Data              []byte              `json:"data"`
+}
+
+// ------------------------------------------------------------------------
+// DmnDiagram Methods.
+// ------------------------------------------------------------------------
+
+// Bytes returns the diagram image.
+func (this *DmnDiagram) Bytes() ([]byte) {
+	return this.Data
+}
+
+// Ext returns the file name extension for the content type of the
+// diagram, including the leading dot, or .bin if the type is unknown.
+func (this *DmnDiagram) Ext() (string) {
+
+	mt, _, err := mime.ParseMediaType(this.ContentType)
+
+	if err != nil {
+		return `.bin`
+	}
+
+	switch strings.ToLower(mt) {
+	case `image/png`:
+		return `.png`
+	case `image/svg+xml`:
+		return `.svg`
+	case `image/jpeg`:
+		return `.jpg`
+	case `image/gif`:
+		return `.gif`
+	}
+
+	if exts, err := mime.ExtensionsByType(mt); err == nil && len(exts) > 0 {
+		return exts[0]
+	}
+
+	return `.bin`
+}
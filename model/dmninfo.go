@@ -14,12 +14,20 @@
 
 package model
 
+import (
+	`fmt`
+	`strings`
+)
+
 // ------------------------------------------------------------------------
 // DmnInfo.
 // ------------------------------------------------------------------------
 
 // DmnInfo contains DMN metadata which can be used to retrieve other data,
-// such as the DMN XML describing the DMN.
+// such as the DMN XML describing the DMN. HistoryTtl is the history time
+// to live in days, or nil if the definition has none. DecisionReqDefId
+// and DecisionReqDefKey identify the decision requirements definition,
+// the Drg of the DMN, when the DMN has more than one decision.
 type DmnInfo struct {
 	Id                string              `json:"id"`
 	Key               string              `json:"key"`
@@ -29,12 +37,43 @@ type DmnInfo struct {
 	Resource          string              `json:"resource"`
 	DeploymentId      string              `json:"deploymentId"`
 	TenantId          string              `json:"tenantId"`
-	DecisionReqDefId  string              `json:"decisionRequirementsDmnId"`
-	DecisionReqDefKey string              `json:"decisionRequirementsDmnKey"`
-	HistoryTtl        string              `json:"historyTimeToLive"`
+	VersionTag        string              `json:"versionTag"`
+	DecisionReqDefId  string              `json:"decisionRequirementsDefinitionId"`
+	DecisionReqDefKey string              `json:"decisionRequirementsDefinitionKey"`
+	HistoryTtl        *int                `json:"historyTimeToLive"`
 	DmnXml            string              `json:"dmnXml"`
 }
 
+// ------------------------------------------------------------------------
+// HistoryTtlError.
+// ------------------------------------------------------------------------
+
+// HistoryTtlError is returned when the history time to live of a decision
+// definition in the engine differs from the one in its DMN XML. Xml is
+// nil if the XML has none or XmlText, the attribute as written, is not a
+// number of days.
+type HistoryTtlError struct {
+	Id                string
+	Engine            *int
+	Xml               *int
+	XmlText           string
+}
+
+// Error implements the error interface for HistoryTtlError.
+func (this *HistoryTtlError) Error() (string) {
+	return fmt.Sprintf(`history time to live of %s is %s in the engine but %s in the DMN XML`,
+		this.Id, ttlString(this.Engine), this.XmlString())
+}
+
+// XmlString renders the history time to live in the DMN XML in days, as
+// none if unset, or quoted as written if it is not a number of days.
+func (this *HistoryTtlError) XmlString() (string) {
+	if this.Xml == nil && strings.TrimSpace(this.XmlText) != `` {
+		return fmt.Sprintf(`%q`, this.XmlText)
+	}
+	return ttlString(this.Xml)
+}
+
 // ------------------------------------------------------------------------
 // DmnInfo Methods.
 // ------------------------------------------------------------------------
@@ -50,3 +89,39 @@ func NewDmnInfo(src interface{}) (*DmnInfo, error) {
 func (this *DmnInfo) Load(src interface{}) error {
 	return load(this, src, `json`)
 }
+
+// CheckHistoryTtl compares the history time to live of the definition, as
+// reported by the engine, with the camunda:historyTimeToLive attribute of
+// its decision in the DMN XML. Updates through the REST API change only
+// the engine value, so the two drift apart until the DMN is redeployed.
+// It returns a *HistoryTtlError holding both values if they differ, or
+// another error if the decision is not in the DMN.
+func (this *DmnInfo) CheckHistoryTtl(dmn *Dmn) (error) {
+
+	d, err := dmn.Decision(this.Key)
+
+	if err != nil {
+		return err
+	}
+
+	engine := this.HistoryTtl
+	xml, err := d.HistoryTtl()
+
+	if err != nil {
+		// The XML value is not a number of days and cannot match.
+	} else if engine == nil && xml == nil {
+		return nil
+	} else if engine != nil && xml != nil && *engine == *xml {
+		return nil
+	}
+
+	return &HistoryTtlError{this.Id, engine, xml, d.HistoryTimeToLive}
+}
+
+// ttlString renders a history time to live in days, or none if unset.
+func ttlString(ttl *int) (string) {
+	if ttl == nil {
+		return `none`
+	}
+	return fmt.Sprintf(`%d days`, *ttl)
+}
@@ -44,23 +44,42 @@ func (this *DmnList) Json() ([]byte, error) {
 	return toJson(this)
 }
 
-// Map creates a map of Dmns indexed by key and version number.
+// Map creates a map of Dmns indexed by tenant ID, key and version number.
 func (this *DmnList) Map() (DmnMap, error) {
 
 	dm := make(DmnMap)
 
 	for _, di := range *this {
 
-		if dm[di.Key] == nil {
-			dm[di.Key] = make(map[int]*DmnInfo)
+		if dm[di.TenantId] == nil {
+			dm[di.TenantId] = make(map[string]map[int]*DmnInfo)
 		}
 
-		dm[di.Key][di.Version] = di
+		if dm[di.TenantId][di.Key] == nil {
+			dm[di.TenantId][di.Key] = make(map[int]*DmnInfo)
+		}
+
+		dm[di.TenantId][di.Key][di.Version] = di
 	}
 
 	return dm, nil
 }
 
+// ByDrd returns the definitions that belong to the decision requirements
+// definition with the given id: the decisions of one Drg.
+func (this *DmnList) ByDrd(id string) (DmnList) {
+
+	var dl DmnList
+
+	for _, di := range *this {
+		if di.DecisionReqDefId != `` && di.DecisionReqDefId == id {
+			dl = append(dl, di)
+		}
+	}
+
+	return dl
+}
+
 // Sort sorts the DmnList by rules defined in byDmnInfo.Less().
 func (this *DmnList) Sort() {
 	sort.Stable(byDmnInfo(*this))
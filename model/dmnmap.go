@@ -20,23 +20,54 @@ import `fmt`
 // DmnMap.
 // ------------------------------------------------------------------------
 
-// DmnMap is a collection of DmnInfo objects indexed by DMN Key and Version.
-type DmnMap map[string]map[int]*DmnInfo
+// DmnMap is a collection of DmnInfo objects indexed by Tenant ID, DMN Key
+// and Version. DMNs that belong to no tenant have an empty Tenant ID.
+type DmnMap map[string]map[string]map[int]*DmnInfo
 
 // ------------------------------------------------------------------------
 // DmnMap Methods.
 // ------------------------------------------------------------------------
 
-// DmnInfo returns a DmnInfo object given its key and version.
+// DmnInfo returns a DmnInfo object that belongs to no tenant given its key
+// and version.
 func (this DmnMap) DmnInfo(key string, ver int) (*DmnInfo, error) {
+	return this.DmnInfoTenant(key, ver, ``)
+}
+
+// DmnInfoTenant returns a DmnInfo object given its key, version and
+// Tenant ID.
+func (this DmnMap) DmnInfoTenant(key string, ver int, tenantId string) (*DmnInfo, error) {
 
-	if di, ok := this[key][ver]; !ok {
+	if di, ok := this[tenantId][key][ver]; !ok && tenantId == `` {
 		return nil, fmt.Errorf(`key %s version %d not found`, key, ver)
+	} else if !ok {
+		return nil, fmt.Errorf(`key %s version %d not found for tenant %s`, key, ver, tenantId)
 	} else {
 		return di, nil
 	}
 }
 
+// Latest returns the DmnInfo object with the highest version of a key
+// within a tenant.
+func (this DmnMap) Latest(key string, tenantId string) (*DmnInfo, error) {
+
+	var latest *DmnInfo
+
+	for _, di := range this[tenantId][key] {
+		if latest == nil || di.Version > latest.Version {
+			latest = di
+		}
+	}
+
+	if latest == nil && tenantId == `` {
+		return nil, fmt.Errorf(`key %s not found`, key)
+	} else if latest == nil {
+		return nil, fmt.Errorf(`key %s not found for tenant %s`, key, tenantId)
+	}
+
+	return latest, nil
+}
+
 // DmnId returns the DMN ID given the DMN Key and Version.
 func (this DmnMap) DmnId(key string, ver int) (string, error) {
 
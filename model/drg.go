@@ -0,0 +1,306 @@
+// Copyright 2018 John Scherff
+//
+// Licensed under the Apache License, version 2.0 (the "License");
+// you may not use this file except in compliance with the License.
+// You may obtain a copy of the License at
+//
+//     http://www.apache.org/licenses/LICENSE-2.0
+//
+// Unless required by applicable law or agreed to in writing, software
+// distributed under the License is distributed on an "AS IS" BASIS,
+// WITHOUT WARRANTIES OR CONDITIONS OF ANY KIND, either express or implied.
+// See the License for the specific language governing permissions and
+// limitations under the License.
+
+package model
+
+import (
+	`fmt`
+	`strings`
+)
+
+// ==============================================================================
+// See https://docs.camunda.org/manual/7.8/reference/dmn11/drg/
+// ==============================================================================
+
+// Kinds of decision requirements graph elements, named for their DMN tags.
+const (
+	DrgDecision               = `decision`
+	DrgInputData              = `inputData`
+	DrgKnowledgeSource        = `knowledgeSource`
+	DrgBusinessKnowledgeModel = `businessKnowledgeModel`
+)
+
+// Kinds of requirements.
+const (
+	RequirementInformation = `information`
+	RequirementKnowledge   = `knowledge`
+	RequirementAuthority   = `authority`
+)
+
+// ------------------------------------------------------------------------
+// CycleError.
+// ------------------------------------------------------------------------
+
+// CycleError is returned when the information and knowledge requirements
+// of a decision requirements graph form a cycle. Ids lists the elements
+// of the cycle, starting and ending with the same element.
+type CycleError struct {
+	Ids               []string
+}
+
+// Error implements the error interface for CycleError.
+func (this *CycleError) Error() (string) {
+	return fmt.Sprintf(`requirement cycle: %s`, strings.Join(this.Ids, ` -> `))
+}
+
+// ------------------------------------------------------------------------
+// Drg.
+// ------------------------------------------------------------------------
+
+// Drg is the decision requirements graph of a Dmn: its decisions, input
+// data, knowledge sources and business knowledge models, linked by their
+// requirements. Key is the id of the definitions element, which Camunda
+// uses as the key of the decision requirements definition and reports
+// as DmnInfo.DecisionReqDefKey of each decision.
+type Drg struct {
+	Key               string
+	Nodes             []*DrgNode
+	nodes             map[string]*DrgNode
+}
+
+// DrgNode is an element of a decision requirements graph. Requirements
+// are the elements it depends on; Dependents are the elements that
+// depend on it.
+type DrgNode struct {
+	Id                string
+	Name              string
+	Kind              string
+	Decision          *Decision
+	Requirements      []*DrgEdge
+	Dependents        []*DrgEdge
+}
+
+// DrgEdge is a requirement between two elements of a decision
+// requirements graph: To requires From.
+type DrgEdge struct {
+	Kind              string
+	From              *DrgNode
+	To                *DrgNode
+}
+
+// ------------------------------------------------------------------------
+// Drg Methods.
+// ------------------------------------------------------------------------
+
+// NewDrg creates the decision requirements graph of a Dmn. Requirements
+// that reference elements the Dmn does not define are an error.
+func NewDrg(dmn *Dmn) (*Drg, error) {
+
+	this := &Drg{Key: dmn.Id, nodes: make(map[string]*DrgNode)}
+
+	// Add the elements in document order, then link the requirements.
+
+	for _, d := range dmn.Decisions {
+		if err := this.add(&DrgNode{Id: d.Id, Name: d.Name, Kind: DrgDecision, Decision: d}); err != nil {
+			return nil, err
+		}
+	}
+	for _, id := range dmn.InputData {
+		if err := this.add(&DrgNode{Id: id.Id, Name: id.Name, Kind: DrgInputData}); err != nil {
+			return nil, err
+		}
+	}
+	for _, ks := range dmn.KnowledgeSources {
+		if err := this.add(&DrgNode{Id: ks.Id, Name: ks.Name, Kind: DrgKnowledgeSource}); err != nil {
+			return nil, err
+		}
+	}
+	for _, bkm := range dmn.BusinessKnowledgeModels {
+		if err := this.add(&DrgNode{Id: bkm.Id, Name: bkm.Name, Kind: DrgBusinessKnowledgeModel}); err != nil {
+			return nil, err
+		}
+	}
+
+	for _, d := range dmn.Decisions {
+		for _, ir := range d.InformationRequirements {
+			if err := this.link(d.Id, RequirementInformation, ir.RequiredDecision, ir.RequiredInput); err != nil {
+				return nil, err
+			}
+		}
+		for _, kr := range d.KnowledgeRequirements {
+			if err := this.link(d.Id, RequirementKnowledge, kr.RequiredKnowledge); err != nil {
+				return nil, err
+			}
+		}
+		for _, ar := range d.AuthorityRequirements {
+			if err := this.link(d.Id, RequirementAuthority, ar.RequiredDecision, ar.RequiredInput, ar.RequiredAuthority); err != nil {
+				return nil, err
+			}
+		}
+	}
+
+	for _, ks := range dmn.KnowledgeSources {
+		for _, ar := range ks.AuthorityRequirements {
+			if err := this.link(ks.Id, RequirementAuthority, ar.RequiredDecision, ar.RequiredInput, ar.RequiredAuthority); err != nil {
+				return nil, err
+			}
+		}
+	}
+
+	for _, bkm := range dmn.BusinessKnowledgeModels {
+		for _, kr := range bkm.KnowledgeRequirements {
+			if err := this.link(bkm.Id, RequirementKnowledge, kr.RequiredKnowledge); err != nil {
+				return nil, err
+			}
+		}
+		for _, ar := range bkm.AuthorityRequirements {
+			if err := this.link(bkm.Id, RequirementAuthority, ar.RequiredDecision, ar.RequiredInput, ar.RequiredAuthority); err != nil {
+				return nil, err
+			}
+		}
+	}
+
+	return this, nil
+}
+
+// add adds an element to the graph.
+func (this *Drg) add(node *DrgNode) (error) {
+
+	if node.Id == `` {
+		return fmt.Errorf(`%s %q has no id`, node.Kind, node.Name)
+	} else if _, ok := this.nodes[node.Id]; ok {
+		return fmt.Errorf(`duplicate element id %s`, node.Id)
+	}
+
+	this.nodes[node.Id] = node
+	this.Nodes = append(this.Nodes, node)
+
+	return nil
+}
+
+// link adds a requirement of an element on each referenced element.
+func (this *Drg) link(id, kind string, refs ...*ElementRef) (error) {
+
+	to := this.nodes[id]
+
+	for _, ref := range refs {
+
+		if ref == nil {
+			continue
+		}
+
+		from, ok := this.nodes[ref.Id()]
+
+		if !ok {
+			return fmt.Errorf(`%s %s requires unknown element %s`, to.Kind, to.Id, ref.Href)
+		}
+
+		edge := &DrgEdge{Kind: kind, From: from, To: to}
+		to.Requirements = append(to.Requirements, edge)
+		from.Dependents = append(from.Dependents, edge)
+	}
+
+	return nil
+}
+
+// Node returns the element with the given id, or nil.
+func (this *Drg) Node(id string) (*DrgNode) {
+	return this.nodes[id]
+}
+
+// Sort returns the elements of the graph in dependency order: every
+// element follows the elements it requires through information and
+// knowledge requirements. Authority requirements do not order elements.
+// Elements are otherwise kept in document order. A cycle is reported as
+// a *CycleError.
+func (this *Drg) Sort() ([]*DrgNode, error) {
+	return this.sort(this.Nodes)
+}
+
+// Dependencies returns the element with the given id and all elements it
+// requires, directly or indirectly, through information and knowledge
+// requirements, in dependency order.
+func (this *Drg) Dependencies(id string) ([]*DrgNode, error) {
+
+	node := this.nodes[id]
+
+	if node == nil {
+		return nil, fmt.Errorf(`unknown element %s`, id)
+	}
+
+	return this.sort([]*DrgNode{node})
+}
+
+// sort orders the given elements and the elements they require by depth
+// first search, detecting cycles through the elements on the search path.
+func (this *Drg) sort(roots []*DrgNode) ([]*DrgNode, error) {
+
+	const (
+		unvisited = iota
+		visiting
+		visited
+	)
+
+	var (
+		order []*DrgNode
+		path []*DrgNode
+		visit func(*DrgNode) (error)
+	)
+
+	state := make(map[*DrgNode]int)
+
+	visit = func(node *DrgNode) (error) {
+
+		switch state[node] {
+
+		case visited:
+			return nil
+
+		case visiting:
+			var ids []string
+			for i := len(path) - 1; i >= 0; i-- {
+				if ids = append(ids, path[i].Id); path[i] == node {
+					break
+				}
+			}
+			for i, j := 0, len(ids) - 1; i < j; i, j = i + 1, j - 1 {
+				ids[i], ids[j] = ids[j], ids[i]
+			}
+			return &CycleError{append(ids, node.Id)}
+		}
+
+		state[node] = visiting
+		path = append(path, node)
+
+		for _, edge := range node.Requirements {
+			if edge.Kind == RequirementAuthority {
+				continue
+			}
+			if err := visit(edge.From); err != nil {
+				return err
+			}
+		}
+
+		path = path[:len(path)-1]
+		state[node] = visited
+		order = append(order, node)
+
+		return nil
+	}
+
+	for _, node := range roots {
+		if err := visit(node); err != nil {
+			return nil, err
+		}
+	}
+
+	return order, nil
+}
+
+// Validate checks that the information and knowledge requirements of the
+// graph are free of cycles.
+func (this *Drg) Validate() (error) {
+	_, err := this.Sort()
+	return err
+}
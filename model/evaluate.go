@@ -0,0 +1,381 @@
+// Copyright 2018 John Scherff
+//
+// Licensed under the Apache License, version 2.0 (the "License");
+// you may not use this file except in compliance with the License.
+// You may obtain a copy of the License at
+//
+//     http://www.apache.org/licenses/LICENSE-2.0
+//
+// Unless required by applicable law or agreed to in writing, software
+// distributed under the License is distributed on an "AS IS" BASIS,
+// WITHOUT WARRANTIES OR CONDITIONS OF ANY KIND, either express or implied.
+// See the License for the specific language governing permissions and
+// limitations under the License.
+
+package model
+
+import (
+	`fmt`
+	`math`
+	`strconv`
+	`strings`
+	`time`
+	`github.com/jscherff/dmnsdk/feel`
+)
+
+// ==============================================================================
+// See https://docs.camunda.org/manual/7.4/reference/dmn11/decision-table/
+// ==============================================================================
+
+// ------------------------------------------------------------------------
+// DecisionResult.
+// ------------------------------------------------------------------------
+
+// ResultEntry maps the output names of a decision table to the output
+// values of a single matched rule.
+type ResultEntry map[string]interface{}
+
+// DecisionResult contains the outcome of evaluating a decision table
+// against a set of input variables: the ids of the rules that matched
+// and the output entries they produced, as selected and ordered by the
+// hit policy of the table.
+type DecisionResult struct {
+	DecisionId        string              `json:"decisionId"`
+	MatchedRules      []string            `json:"matchedRules"`
+	Entries           []ResultEntry       `json:"entries"`
+}
+
+// merge adds the outputs of a DecisionResult of a Decision to a set of
+// variables, keyed by output name.
+func (this *DecisionResult) merge(d *Decision, vars map[string]interface{}) {
+
+	for _, o := range d.DecisionTable.Outputs {
+
+		switch len(this.Entries) {
+
+		case 0:
+			vars[o.Name] = nil
+
+		case 1:
+			vars[o.Name] = this.Entries[0][o.Name]
+
+		default:
+			vals := make([]interface{}, len(this.Entries))
+			for i, e := range this.Entries {
+				vals[i] = e[o.Name]
+			}
+			vars[o.Name] = vals
+		}
+	}
+}
+
+// ------------------------------------------------------------------------
+// Evaluation Methods.
+// ------------------------------------------------------------------------
+
+// Evaluate evaluates the decision table of the Dmn against the supplied
+// input variables. The Dmn must have exactly one decision; otherwise use
+// Decision to select the decision to evaluate.
+func (this *Dmn) Evaluate(vars map[string]interface{}) (*DecisionResult, error) {
+
+	if d, err := this.only(); err != nil {
+		return nil, err
+	} else {
+		return d.Evaluate(vars)
+	}
+}
+
+// EvaluateDecision evaluates the decision with the given id against the
+// supplied input variables, first evaluating the decisions it requires
+// in dependency order. The result of each required decision is added to
+// the variables of the decisions that follow it: each output name maps
+// to the output value of a single result entry, to a list of the output
+// values of several result entries, or to nil when no rule matched.
+func (this *Dmn) EvaluateDecision(id string, vars map[string]interface{}) (*DecisionResult, error) {
+
+	var (
+		drg *Drg
+		nodes []*DrgNode
+		dr *DecisionResult
+		err error
+	)
+
+	if _, err = this.Decision(id); err != nil {
+		return nil, err
+	} else if drg, err = this.Drg(); err != nil {
+		return nil, err
+	} else if nodes, err = drg.Dependencies(id); err != nil {
+		return nil, err
+	}
+
+	chain := make(map[string]interface{}, len(vars))
+
+	for k, v := range vars {
+		chain[k] = v
+	}
+
+	for _, node := range nodes {
+
+		if node.Kind != DrgDecision {
+			continue
+		} else if dr, err = node.Decision.Evaluate(chain); err != nil {
+			return nil, err
+		} else if node.Id != id {
+			dr.merge(node.Decision, chain)
+		}
+	}
+
+	return dr, nil
+}
+
+// Evaluate evaluates the decision table of the Decision against the
+// supplied input variables.
+func (this *Decision) Evaluate(vars map[string]interface{}) (*DecisionResult, error) {
+
+	if _, err := this.Table(); err != nil {
+		return nil, err
+	}
+
+	if dr, err := this.DecisionTable.Evaluate(vars); err != nil {
+		return nil, fmt.Errorf(`decision %s: %v`, this.Id, err)
+	} else {
+		dr.DecisionId = this.Id
+		return dr, nil
+	}
+}
+
+// Evaluate binds each input expression of the DecisionTable to the
+// supplied variables, tests the input entries of every rule against the
+// resulting input values, and returns the output entries of the rules
+// that matched, reduced according to the hit policy of the table.
+func (this *DecisionTable) Evaluate(vars map[string]interface{}) (*DecisionResult, error) {
+
+	exps := this.InputExpressions()
+	values := make([]interface{}, len(exps))
+
+	for i, exp := range exps {
+		if val, err := exp.Evaluate(vars); err != nil {
+			return nil, err
+		} else {
+			values[i] = val
+		}
+	}
+
+	dr := &DecisionResult{
+		MatchedRules: []string{},
+		Entries: []ResultEntry{},
+	}
+
+	for _, rule := range this.Rules {
+
+		if len(rule.InputEntries) != len(values) {
+			return nil, fmt.Errorf(`rule %s has %d input entries, expected %d`,
+				rule.Id, len(rule.InputEntries), len(values))
+		}
+
+		if ok, err := rule.Matches(values, vars); err != nil {
+			return nil, err
+		} else if !ok {
+			continue
+		} else if re, err := rule.Outputs(this.Outputs, vars); err != nil {
+			return nil, err
+		} else {
+			dr.MatchedRules = append(dr.MatchedRules, rule.Id)
+			dr.Entries = append(dr.Entries, re)
+		}
+	}
+
+	if err := this.applyHitPolicy(dr); err != nil {
+		return nil, err
+	}
+
+	return dr, nil
+}
+
+// InputExpressions returns the input expressions of all inputs in column
+// order, matching the order of the input entries in each rule.
+func (this *DecisionTable) InputExpressions() (exps []*InputExpression) {
+	for _, input := range this.Inputs {
+		exps = append(exps, input.InputExpressions...)
+	}
+	return exps
+}
+
+// Evaluate binds the InputExpression to the supplied variables and
+// converts the result to the type named by TypeRef.
+func (this *InputExpression) Evaluate(vars map[string]interface{}) (interface{}, error) {
+
+	text := strings.TrimSpace(this.Text)
+
+	if text == `` {
+		return nil, fmt.Errorf(`input expression %s is empty`, this.Id)
+	} else if val, err := evalExpression(text, vars); err != nil {
+		return nil, fmt.Errorf(`input expression %s: %v`, this.Id, err)
+	} else if val, err := convertValue(val, this.TypeRef); err != nil {
+		return nil, fmt.Errorf(`input expression %s: %v`, this.Id, err)
+	} else {
+		return val, nil
+	}
+}
+
+// Matches reports whether every input entry of the Rule is satisfied by
+// the corresponding input value.
+func (this *Rule) Matches(values []interface{}, vars map[string]interface{}) (bool, error) {
+
+	for i, entry := range this.InputEntries {
+		if ok, err := matchUnaryTests(entry.Text, values[i], vars); err != nil {
+			return false, fmt.Errorf(`rule %s input entry %s: %v`, this.Id, entry.Id, err)
+		} else if !ok {
+			return false, nil
+		}
+	}
+
+	return true, nil
+}
+
+// Outputs evaluates the output entries of the Rule and returns them keyed
+// by output name. Empty output entries are not part of the result.
+func (this *Rule) Outputs(outputs []*Output, vars map[string]interface{}) (ResultEntry, error) {
+
+	if len(this.OutputEntries) != len(outputs) {
+		return nil, fmt.Errorf(`rule %s has %d output entries, expected %d`,
+			this.Id, len(this.OutputEntries), len(outputs))
+	}
+
+	re := make(ResultEntry)
+
+	for i, entry := range this.OutputEntries {
+
+		text := strings.TrimSpace(entry.Text)
+
+		if text == `` {
+			continue
+		}
+
+		if val, err := evalExpression(text, vars); err != nil {
+			return nil, fmt.Errorf(`rule %s output entry %s: %v`, this.Id, entry.Id, err)
+		} else if val, err := convertValue(val, outputs[i].TypeRef); err != nil {
+			return nil, fmt.Errorf(`rule %s output entry %s: %v`, this.Id, entry.Id, err)
+		} else {
+			re[outputs[i].Name] = val
+		}
+	}
+
+	return re, nil
+}
+
+// ------------------------------------------------------------------------
+// Evaluation Helpers.
+// ------------------------------------------------------------------------
+
+// evalExpression parses and evaluates a FEEL simple expression.
+func evalExpression(text string, vars map[string]interface{}) (interface{}, error) {
+
+	if x, err := feel.ParseExpression(text); err != nil {
+		return nil, err
+	} else {
+		return feel.Eval(x, vars)
+	}
+}
+
+// matchUnaryTests reports whether a value satisfies the FEEL simple unary
+// tests of an input entry.
+func matchUnaryTests(text string, val interface{}, vars map[string]interface{}) (bool, error) {
+
+	if ut, err := feel.ParseUnaryTests(text); err != nil {
+		return false, err
+	} else {
+		return feel.Match(ut, val, vars)
+	}
+}
+
+// convertValue converts a value to the type named by a DMN typeRef. An
+// empty typeRef leaves the value unchanged.
+func convertValue(val interface{}, typeRef string) (interface{}, error) {
+
+	if val == nil {
+		return nil, nil
+	}
+
+	switch strings.ToLower(typeRef) {
+
+	case ``:
+		return val, nil
+
+	case `string`:
+		if s, ok := val.(string); ok {
+			return s, nil
+		}
+		return fmt.Sprint(val), nil
+
+	case `integer`, `long`:
+		if f, ok := toFloat(val); !ok {
+			return nil, fmt.Errorf(`cannot convert %v to %s`, val, typeRef)
+		} else if f != math.Trunc(f) {
+			return nil, fmt.Errorf(`cannot convert %v to %s`, val, typeRef)
+		} else {
+			return int64(f), nil
+		}
+
+	case `double`:
+		if f, ok := toFloat(val); !ok {
+			return nil, fmt.Errorf(`cannot convert %v to %s`, val, typeRef)
+		} else {
+			return f, nil
+		}
+
+	case `boolean`:
+		switch v := val.(type) {
+		case bool:
+			return v, nil
+		case string:
+			if b, err := strconv.ParseBool(v); err == nil {
+				return b, nil
+			}
+		}
+		return nil, fmt.Errorf(`cannot convert %v to %s`, val, typeRef)
+
+	case `date`:
+		switch v := val.(type) {
+		case time.Time:
+			return v, nil
+		case string:
+			if t, err := parseDateTime(v); err == nil {
+				return t, nil
+			}
+		}
+		return nil, fmt.Errorf(`cannot convert %v to %s`, val, typeRef)
+
+	default:
+		return nil, fmt.Errorf(`unsupported type %s`, typeRef)
+	}
+}
+
+// toFloat converts numeric values and numeric strings to float64.
+func toFloat(val interface{}) (float64, bool) {
+
+	if f, ok := feel.ToNumber(val); ok {
+		return f, true
+	}
+
+	switch v := val.(type) {
+	case string:
+		if f, err := strconv.ParseFloat(strings.TrimSpace(v), 64); err == nil {
+			return f, true
+		}
+	}
+
+	return 0, false
+}
+
+// parseDateTime parses the date formats accepted by the Camunda engine.
+func parseDateTime(s string) (time.Time, error) {
+
+	for _, layout := range []string{`2006-01-02T15:04:05`, time.RFC3339, `2006-01-02`} {
+		if t, err := time.Parse(layout, s); err == nil {
+			return t, nil
+		}
+	}
+
+	return time.Time{}, fmt.Errorf(`invalid date %q`, s)
+}
@@ -23,6 +23,7 @@ import (
 	`net/http`
 	`os`
 	`strings`
+	`time`
 )
 
 const (
@@ -30,6 +31,12 @@ const (
 	jsonIndent = `    `
 )
 
+// HttpClient is the client used to load objects from URL sources. Its
+// timeout keeps an unresponsive server from blocking a load forever;
+// replace it to change the timeout or transport. The api package uses its
+// own configurable client and does not depend on it.
+var HttpClient = &http.Client{Timeout: 30 * time.Second}
+
 // load unmarshals JSON or XML from an io.Reader.
 func load(dst interface{}, src interface{}, enc string) (err error) {
 
@@ -62,8 +69,6 @@ func load(dst interface{}, src interface{}, enc string) (err error) {
 	default:
 		return fmt.Errorf(`unsupported source: %T`, obj)
 	}
-
-	return nil
 }
 
 // read returns an io.Reader ready for unmarshalling.
@@ -88,7 +93,7 @@ func read(w io.Writer, s string) (int64, error) {
 // readUrl returns a buffer filled from a URL.
 func readUrl(w io.Writer, u string) (int64, error) {
 
-	if resp, err := http.Get(u); err != nil {
+	if resp, err := HttpClient.Get(u); err != nil {
 		return 0, err
 	} else {
 		defer resp.Body.Close()
@@ -0,0 +1,334 @@
+// Copyright 2018 John Scherff
+//
+// Licensed under the Apache License, version 2.0 (the "License");
+// you may not use this file except in compliance with the License.
+// You may obtain a copy of the License at
+//
+//     http://www.apache.org/licenses/LICENSE-2.0
+//
+// Unless required by applicable law or agreed to in writing, software
+// distributed under the License is distributed on an "AS IS" BASIS,
+// WITHOUT WARRANTIES OR CONDITIONS OF ANY KIND, either express or implied.
+// See the License for the specific language governing permissions and
+// limitations under the License.
+
+package model
+
+import (
+	`fmt`
+	`sort`
+	`strings`
+	`github.com/jscherff/dmnsdk/feel`
+)
+
+// ==============================================================================
+// See https://docs.camunda.org/manual/7.4/reference/dmn11/decision-table/hit-policy/
+// ==============================================================================
+
+const (
+	HitPolicyUnique      = `UNIQUE`
+	HitPolicyFirst       = `FIRST`
+	HitPolicyPriority    = `PRIORITY`
+	HitPolicyAny         = `ANY`
+	HitPolicyCollect     = `COLLECT`
+	HitPolicyRuleOrder   = `RULE ORDER`
+	HitPolicyOutputOrder = `OUTPUT ORDER`
+)
+
+const (
+	AggregationSum   = `SUM`
+	AggregationMin   = `MIN`
+	AggregationMax   = `MAX`
+	AggregationCount = `COUNT`
+)
+
+// ------------------------------------------------------------------------
+// HitPolicyError.
+// ------------------------------------------------------------------------
+
+// HitPolicyError is returned when the rules matched during evaluation
+// violate the hit policy of the decision table.
+type HitPolicyError struct {
+	HitPolicy         string
+	RuleIds           []string
+	Reason            string
+}
+
+// Error implements the error interface for HitPolicyError.
+func (this *HitPolicyError) Error() (string) {
+	return fmt.Sprintf(`hit policy %s violated: %s (rules %s)`,
+		this.HitPolicy, this.Reason, strings.Join(this.RuleIds, `, `),
+	)
+}
+
+// ------------------------------------------------------------------------
+// Hit Policy Methods.
+// ------------------------------------------------------------------------
+
+// hitPolicy returns the normalized hit policy of the DecisionTable,
+// defaulting to UNIQUE.
+func (this *DecisionTable) hitPolicy() (string) {
+	if hp := strings.ToUpper(strings.TrimSpace(this.HitPolicy)); hp != `` {
+		return hp
+	}
+	return HitPolicyUnique
+}
+
+// applyHitPolicy reduces the rules matched by an evaluation to the
+// decision result dictated by the hit policy and aggregation.
+func (this *DecisionTable) applyHitPolicy(dr *DecisionResult) (error) {
+
+	hp, agg := this.hitPolicy(), strings.ToUpper(strings.TrimSpace(this.Aggregation))
+
+	if agg != `` && hp != HitPolicyCollect {
+		return fmt.Errorf(`aggregation %s requires hit policy %s, not %s`,
+			agg, HitPolicyCollect, hp)
+	}
+
+	switch hp {
+
+	case HitPolicyUnique:
+		if len(dr.MatchedRules) > 1 {
+			return &HitPolicyError{hp, dr.MatchedRules, `multiple rules matched`}
+		}
+
+	case HitPolicyFirst:
+		if len(dr.MatchedRules) > 1 {
+			dr.MatchedRules = dr.MatchedRules[:1]
+			dr.Entries = dr.Entries[:1]
+		}
+
+	case HitPolicyAny:
+		for i := 1; i < len(dr.Entries); i++ {
+			if !dr.Entries[i].equal(dr.Entries[0]) {
+				return &HitPolicyError{hp, dr.MatchedRules, `matched rules have different outputs`}
+			}
+		}
+		if len(dr.MatchedRules) > 1 {
+			dr.MatchedRules = dr.MatchedRules[:1]
+			dr.Entries = dr.Entries[:1]
+		}
+
+	case HitPolicyPriority, HitPolicyOutputOrder:
+		if err := this.sortByPriority(dr); err != nil {
+			return err
+		}
+		if hp == HitPolicyPriority && len(dr.MatchedRules) > 1 {
+			dr.MatchedRules = dr.MatchedRules[:1]
+			dr.Entries = dr.Entries[:1]
+		}
+
+	case HitPolicyCollect:
+		if agg != `` {
+			return this.aggregate(dr, agg)
+		}
+
+	case HitPolicyRuleOrder:
+
+	default:
+		return fmt.Errorf(`unsupported hit policy %s`, this.HitPolicy)
+	}
+
+	return nil
+}
+
+// sortByPriority orders the matched rules by the position of their output
+// values in the output values list of each output, highest priority first.
+func (this *DecisionTable) sortByPriority(dr *DecisionResult) (error) {
+
+	var lists [][]interface{}
+
+	for _, output := range this.Outputs {
+
+		if output.OutputValues == nil || strings.TrimSpace(output.OutputValues.Text) == `` {
+			lists = append(lists, nil)
+			continue
+		}
+
+		if list, err := output.OutputValues.Values(output.TypeRef); err != nil {
+			return fmt.Errorf(`output %s values: %v`, output.Id, err)
+		} else {
+			lists = append(lists, list)
+		}
+	}
+
+	priority := func(re ResultEntry) (p []int) {
+		for i, output := range this.Outputs {
+			if lists[i] == nil {
+				continue
+			}
+			rank := len(lists[i])
+			for j, val := range lists[i] {
+				if feel.Equal(re[output.Name], val) {
+					rank = j
+					break
+				}
+			}
+			p = append(p, rank)
+		}
+		return p
+	}
+
+	idx := make([]int, len(dr.Entries))
+	prio := make([][]int, len(dr.Entries))
+
+	for i, re := range dr.Entries {
+		idx[i], prio[i] = i, priority(re)
+		if len(prio[i]) == 0 {
+			return fmt.Errorf(`hit policy %s requires output values`, this.hitPolicy())
+		}
+	}
+
+	sort.SliceStable(idx, func(i, j int) bool {
+		pi, pj := prio[idx[i]], prio[idx[j]]
+		for k := range pi {
+			if pi[k] != pj[k] {
+				return pi[k] < pj[k]
+			}
+		}
+		return false
+	})
+
+	rules := make([]string, len(idx))
+	entries := make([]ResultEntry, len(idx))
+
+	for i, j := range idx {
+		rules[i], entries[i] = dr.MatchedRules[j], dr.Entries[j]
+	}
+
+	dr.MatchedRules, dr.Entries = rules, entries
+
+	return nil
+}
+
+// aggregate reduces the output values of all matched rules to a single
+// result entry using the SUM, MIN, MAX or COUNT aggregator.
+func (this *DecisionTable) aggregate(dr *DecisionResult, agg string) (error) {
+
+	if len(this.Outputs) != 1 {
+		return fmt.Errorf(`aggregation %s requires exactly one output, found %d`,
+			agg, len(this.Outputs))
+	}
+
+	name := this.Outputs[0].Name
+
+	var values []interface{}
+
+	for _, re := range dr.Entries {
+		if val, ok := re[name]; ok && val != nil {
+			values = append(values, val)
+		}
+	}
+
+	var result interface{}
+
+	switch agg {
+
+	case AggregationCount:
+		result = int64(len(values))
+
+	case AggregationSum:
+
+		if len(values) == 0 {
+			break
+		}
+
+		var isum, fsum = int64(0), float64(0)
+		integral := true
+
+		for _, val := range values {
+			if i, ok := val.(int64); ok {
+				isum += i
+				fsum += float64(i)
+			} else if f, ok := feel.ToNumber(val); ok {
+				integral = false
+				fsum += f
+			} else {
+				return fmt.Errorf(`aggregation %s: %v is not a number`, agg, val)
+			}
+		}
+
+		if integral {
+			result = isum
+		} else {
+			result = fsum
+		}
+
+	case AggregationMin, AggregationMax:
+
+		for _, val := range values {
+			if _, ok := feel.ToNumber(val); !ok {
+				return fmt.Errorf(`aggregation %s: %v is not a number`, agg, val)
+			} else if result == nil {
+				result = val
+			} else if c, err := feel.Compare(val, result); err != nil {
+				return err
+			} else if (agg == AggregationMin && c < 0) || (agg == AggregationMax && c > 0) {
+				result = val
+			}
+		}
+
+	default:
+		return fmt.Errorf(`unsupported aggregation %s`, this.Aggregation)
+	}
+
+	dr.Entries = []ResultEntry{}
+
+	if result != nil {
+		dr.Entries = append(dr.Entries, ResultEntry{name: result})
+	}
+
+	return nil
+}
+
+// ------------------------------------------------------------------------
+// UnaryTests Methods.
+// ------------------------------------------------------------------------
+
+// Values returns the literal values of an allowed-value list, converted to
+// the type named by typeRef, in order of decreasing priority.
+func (this *UnaryTests) Values(typeRef string) (values []interface{}, err error) {
+
+	ut, err := feel.ParseUnaryTests(this.Text)
+
+	if err != nil {
+		return nil, err
+	} else if ut.Not {
+		return nil, fmt.Errorf(`negated list %s is not a list of values`, ut)
+	}
+
+	for _, test := range ut.Tests {
+
+		if cmp, ok := test.(*feel.Comparison); !ok || cmp.Op != `=` {
+			return nil, fmt.Errorf(`%s is not a value`, test)
+		} else if val, err := feel.Eval(cmp.Endpoint, nil); err != nil {
+			return nil, err
+		} else if val, err := convertValue(val, typeRef); err != nil {
+			return nil, err
+		} else {
+			values = append(values, val)
+		}
+	}
+
+	return values, nil
+}
+
+// ------------------------------------------------------------------------
+// ResultEntry Methods.
+// ------------------------------------------------------------------------
+
+// equal reports whether two result entries have the same output values.
+func (this ResultEntry) equal(re ResultEntry) (bool) {
+
+	if len(this) != len(re) {
+		return false
+	}
+
+	for name, val := range this {
+		if other, ok := re[name]; !ok || !feel.Equal(val, other) {
+			return false
+		}
+	}
+
+	return true
+}
@@ -0,0 +1,176 @@
+package model
+
+import (
+	`reflect`
+	`testing`
+)
+
+// testTable creates a decision table with an integer input x and a single
+// output y. Each rule is a pair of an input entry and an output entry and
+// is identified by its position, starting with r1.
+func testTable(hitPolicy, aggregation, typeRef, outputValues string, rules ...[2]string) (*DecisionTable) {
+
+	dt := &DecisionTable{
+		HitPolicy: hitPolicy,
+		Aggregation: aggregation,
+		Inputs: []*Input{{InputExpressions: []*InputExpression{{Id: `x`, Text: `x`, TypeRef: `integer`}}}},
+		Outputs: []*Output{{Id: `y`, Name: `y`, TypeRef: typeRef}},
+	}
+
+	if outputValues != `` {
+		dt.Outputs[0].OutputValues = &UnaryTests{Text: outputValues}
+	}
+
+	for i, r := range rules {
+		dt.Rules = append(dt.Rules, &Rule{
+			Id: `r` + string(rune('1' + i)),
+			InputEntries: []*InputEntry{{Text: r[0]}},
+			OutputEntries: []*OutputEntry{{Text: r[1]}},
+		})
+	}
+
+	return dt
+}
+
+func TestHitPolicy(t *testing.T) {
+
+	tests := []struct {
+		name	string
+		table	*DecisionTable
+		rules	[]string
+		values	[]interface{}
+		err	bool
+	}{
+		{`unique one match`,
+			testTable(``, ``, `string`, ``, [2]string{`< 2`, `"a"`}, [2]string{`>= 2`, `"b"`}),
+			[]string{`r2`}, []interface{}{`b`}, false},
+		{`unique no match`,
+			testTable(`UNIQUE`, ``, `string`, ``, [2]string{`> 5`, `"a"`}),
+			[]string{}, []interface{}{}, false},
+		{`unique violated`,
+			testTable(`UNIQUE`, ``, `string`, ``, [2]string{`> 1`, `"a"`}, [2]string{`> 2`, `"b"`}),
+			nil, nil, true},
+		{`first`,
+			testTable(`FIRST`, ``, `string`, ``, [2]string{`> 5`, `"a"`}, [2]string{`> 1`, `"b"`}, [2]string{``, `"c"`}),
+			[]string{`r2`}, []interface{}{`b`}, false},
+		{`any different outputs`,
+			testTable(`ANY`, ``, `string`, ``, [2]string{`> 1`, `"a"`}, [2]string{`< 5`, `"b"`}, [2]string{``, `"a"`}, [2]string{`> 2`, `"a"`}),
+			nil, nil, true},
+		{`any same outputs`,
+			testTable(`ANY`, ``, `string`, ``, [2]string{`> 5`, `"b"`}, [2]string{``, `"a"`}, [2]string{`> 2`, `"a"`}),
+			[]string{`r2`}, []interface{}{`a`}, false},
+		{`any list outputs`,
+			testTable(`ANY`, ``, ``, ``, [2]string{``, `l`}, [2]string{`> 1`, `l`}),
+			[]string{`r1`}, []interface{}{[]interface{}{`a`, `b`}}, false},
+		{`priority`,
+			testTable(`PRIORITY`, ``, `string`, `"high", "medium", "low"`,
+				[2]string{``, `"low"`}, [2]string{`> 1`, `"high"`}, [2]string{`< 5`, `"medium"`}),
+			[]string{`r2`}, []interface{}{`high`}, false},
+		{`priority without output values`,
+			testTable(`PRIORITY`, ``, `string`, ``, [2]string{``, `"low"`}),
+			nil, nil, true},
+		{`output order`,
+			testTable(`OUTPUT ORDER`, ``, `string`, `"high", "medium", "low"`,
+				[2]string{``, `"low"`}, [2]string{`> 1`, `"high"`}, [2]string{`< 5`, `"medium"`}),
+			[]string{`r2`, `r3`, `r1`}, []interface{}{`high`, `medium`, `low`}, false},
+		{`rule order`,
+			testTable(`RULE ORDER`, ``, `string`, ``, [2]string{``, `"c"`}, [2]string{`> 1`, `"a"`}, [2]string{`> 5`, `"b"`}),
+			[]string{`r1`, `r2`}, []interface{}{`c`, `a`}, false},
+		{`collect`,
+			testTable(`COLLECT`, ``, `string`, ``, [2]string{``, `"c"`}, [2]string{`> 1`, `"a"`}),
+			[]string{`r1`, `r2`}, []interface{}{`c`, `a`}, false},
+		{`aggregation without collect`,
+			testTable(`FIRST`, `SUM`, `integer`, ``, [2]string{``, `1`}),
+			nil, nil, true},
+		{`unsupported hit policy`,
+			testTable(`RANDOM`, ``, `string`, ``, [2]string{``, `"a"`}),
+			nil, nil, true},
+	}
+
+	vars := map[string]interface{}{`x`: 3, `l`: []interface{}{`a`, `b`}}
+
+	for _, tt := range tests {
+
+		dr, err := tt.table.Evaluate(vars)
+
+		if tt.err {
+			if err == nil {
+				t.Errorf(`%s: Evaluate = %v, want error`, tt.name, dr.Entries)
+			}
+			continue
+		} else if err != nil {
+			t.Errorf(`%s: Evaluate: %v`, tt.name, err)
+			continue
+		}
+
+		values := []interface{}{}
+
+		for _, re := range dr.Entries {
+			values = append(values, re[`y`])
+		}
+
+		if !reflect.DeepEqual(dr.MatchedRules, tt.rules) || !reflect.DeepEqual(values, tt.values) {
+			t.Errorf(`%s: Evaluate = %v %v, want %v %v`, tt.name, dr.MatchedRules, values, tt.rules, tt.values)
+		}
+
+		if len(dr.MatchedRules) != len(dr.Entries) {
+			t.Errorf(`%s: %d matched rules but %d entries`, tt.name, len(dr.MatchedRules), len(dr.Entries))
+		}
+	}
+}
+
+func TestAggregation(t *testing.T) {
+
+	tests := []struct {
+		name	string
+		table	*DecisionTable
+		want	[]ResultEntry
+		err	bool
+	}{
+		{`sum integers`,
+			testTable(`COLLECT`, `SUM`, `integer`, ``, [2]string{``, `1`}, [2]string{`> 1`, `2`}, [2]string{`> 5`, `4`}),
+			[]ResultEntry{{`y`: int64(3)}}, false},
+		{`sum doubles`,
+			testTable(`COLLECT`, `SUM`, `double`, ``, [2]string{``, `1.5`}, [2]string{``, `2`}),
+			[]ResultEntry{{`y`: 3.5}}, false},
+		{`sum no match`,
+			testTable(`COLLECT`, `SUM`, `integer`, ``, [2]string{`> 5`, `1`}),
+			[]ResultEntry{}, false},
+		{`sum skips empty outputs`,
+			testTable(`COLLECT`, `SUM`, `integer`, ``, [2]string{``, ``}, [2]string{``, `2`}),
+			[]ResultEntry{{`y`: int64(2)}}, false},
+		{`min`,
+			testTable(`COLLECT`, `MIN`, `integer`, ``, [2]string{``, `5`}, [2]string{``, `2`}, [2]string{``, `7`}),
+			[]ResultEntry{{`y`: int64(2)}}, false},
+		{`max`,
+			testTable(`COLLECT`, `MAX`, `integer`, ``, [2]string{``, `5`}, [2]string{``, `2`}, [2]string{``, `7`}),
+			[]ResultEntry{{`y`: int64(7)}}, false},
+		{`count`,
+			testTable(`COLLECT`, `COUNT`, `string`, ``, [2]string{``, `"a"`}, [2]string{``, `"a"`}, [2]string{`> 5`, `"b"`}),
+			[]ResultEntry{{`y`: int64(2)}}, false},
+		{`count no match`,
+			testTable(`COLLECT`, `COUNT`, `string`, ``, [2]string{`> 5`, `"a"`}),
+			[]ResultEntry{{`y`: int64(0)}}, false},
+		{`sum of strings`,
+			testTable(`COLLECT`, `SUM`, `string`, ``, [2]string{``, `"a"`}),
+			nil, true},
+		{`unsupported aggregation`,
+			testTable(`COLLECT`, `AVG`, `integer`, ``, [2]string{``, `1`}),
+			nil, true},
+	}
+
+	for _, tt := range tests {
+
+		dr, err := tt.table.Evaluate(map[string]interface{}{`x`: 3})
+
+		if tt.err {
+			if err == nil {
+				t.Errorf(`%s: Evaluate = %v, want error`, tt.name, dr.Entries)
+			}
+		} else if err != nil {
+			t.Errorf(`%s: Evaluate: %v`, tt.name, err)
+		} else if !reflect.DeepEqual(dr.Entries, tt.want) {
+			t.Errorf(`%s: Evaluate = %v, want %v`, tt.name, dr.Entries, tt.want)
+		}
+	}
+}
@@ -0,0 +1,384 @@
+// Copyright 2018 John Scherff
+//
+// Licensed under the Apache License, version 2.0 (the "License");
+// you may not use this file except in compliance with the License.
+// You may obtain a copy of the License at
+//
+//     http://www.apache.org/licenses/LICENSE-2.0
+//
+// Unless required by applicable law or agreed to in writing, software
+// distributed under the License is distributed on an "AS IS" BASIS,
+// WITHOUT WARRANTIES OR CONDITIONS OF ANY KIND, either express or implied.
+// See the License for the specific language governing permissions and
+// limitations under the License.
+
+package model
+
+import (
+	`bytes`
+	`encoding/xml`
+	`fmt`
+	`html/template`
+	`io`
+	`strings`
+	`unicode/utf8`
+)
+
+// ------------------------------------------------------------------------
+// Table View.
+// ------------------------------------------------------------------------
+
+// tableView is the layout of a DecisionTable shared by the renderers: a
+// column per input expression and per output, and a row per rule. As in
+// NewDmnRules, the rule description, which the Modeler shows as the rule
+// annotation, and a column per annotation clause come last.
+type tableView struct {
+	Title             string
+	HitPolicy         string
+	Badge             string
+	Inputs            []*columnView
+	Outputs           []*columnView
+	Annotations       []string
+	Rows              []*rowView
+}
+
+// columnView is the header of an input or output column.
+type columnView struct {
+	Label             string
+	Text              string
+	TypeRef           string
+}
+
+// rowView is a rule with its entries in column order.
+type rowView struct {
+	Number            int
+	Id                string
+	Inputs            []string
+	Outputs           []string
+	Annotations       []string
+}
+
+// hitPolicyBadges are the single letter abbreviations of the hit policies
+// and aggregations shown by the Camunda Modeler.
+var hitPolicyBadges = map[string]string{
+	HitPolicyUnique:      `U`,
+	HitPolicyFirst:       `F`,
+	HitPolicyPriority:    `P`,
+	HitPolicyAny:         `A`,
+	HitPolicyCollect:     `C`,
+	HitPolicyRuleOrder:   `R`,
+	HitPolicyOutputOrder: `O`,
+	AggregationSum:       `+`,
+	AggregationMin:       `<`,
+	AggregationMax:       `>`,
+	AggregationCount:     `#`,
+}
+
+// view lays out the DecisionTable for rendering.
+func (this *DecisionTable) view(title string) (*tableView) {
+
+	hp, agg := this.hitPolicy(), strings.ToUpper(strings.TrimSpace(this.Aggregation))
+
+	tv := &tableView{
+		Title: title,
+		HitPolicy: hp,
+		Badge: hitPolicyBadges[hp] + hitPolicyBadges[agg],
+	}
+
+	if agg != `` {
+		tv.HitPolicy += ` ` + agg
+	}
+
+	for _, input := range this.Inputs {
+		for _, inputExp := range input.InputExpressions {
+			tv.Inputs = append(tv.Inputs, &columnView{input.Label, inputExp.Text, inputExp.TypeRef})
+		}
+	}
+
+	for _, output := range this.Outputs {
+		tv.Outputs = append(tv.Outputs, &columnView{output.Label, output.Name, output.TypeRef})
+	}
+
+	tv.Annotations = []string{`Annotation`}
+
+	for _, annotation := range this.Annotations {
+		tv.Annotations = append(tv.Annotations, annotation.Name)
+	}
+
+	for i, rule := range this.Rules {
+
+		row := &rowView{
+			Number: i + 1,
+			Id: rule.Id,
+			Inputs: make([]string, len(tv.Inputs)),
+			Outputs: make([]string, len(tv.Outputs)),
+			Annotations: make([]string, len(tv.Annotations)),
+		}
+
+		for j, entry := range rule.InputEntries {
+			if j < len(row.Inputs) {
+				row.Inputs[j] = entry.Text
+			}
+		}
+
+		for j, entry := range rule.OutputEntries {
+			if j < len(row.Outputs) {
+				row.Outputs[j] = entry.Text
+			}
+		}
+
+		row.Annotations[0] = rule.Description
+
+		for j, entry := range rule.AnnotationEntries {
+			if j+1 < len(row.Annotations) {
+				row.Annotations[j+1] = entry.Text
+			}
+		}
+
+		tv.Rows = append(tv.Rows, row)
+	}
+
+	return tv
+}
+
+// ------------------------------------------------------------------------
+// HTML.
+// ------------------------------------------------------------------------
+
+var htmlTemplate = template.Must(template.New(`table`).Parse(`<!DOCTYPE html>
+<html>
+<head>
+<meta charset="utf-8">
+<title>{{.Title}}</title>
+<style>
+body { font-family: sans-serif; font-size: 13px; margin: 16px; color: #222; }
+h1 { font-size: 16px; }
+table { border-collapse: collapse; }
+th, td { border: 1px solid #999; padding: 4px 8px; text-align: left; vertical-align: top; white-space: pre-wrap; }
+th { background: #f4f4f4; }
+th.badge { background: #fff; text-align: center; font-size: 15px; }
+th.input { background: #e8f0fa; }
+th.annotation, td.annotation { color: #555; font-style: italic; }
+td.rule { color: #777; text-align: right; }
+td.output, th.output { border-left: 3px double #999; }
+.text { font-family: monospace; font-weight: normal; }
+.type { color: #777; font-weight: normal; font-style: italic; }
+</style>
+</head>
+<body>
+<h1>{{.Title}}</h1>
+<table>
+<thead>
+<tr>
+<th class="badge" title="{{.HitPolicy}}">{{.Badge}}</th>
+{{- range .Inputs}}
+<th class="input">{{.Label}}<div class="text">{{.Text}}</div><div class="type">{{.TypeRef}}</div></th>
+{{- end}}
+{{- range $i, $c := .Outputs}}
+<th{{if eq $i 0}} class="output"{{end}}>{{$c.Label}}<div class="text">{{$c.Text}}</div><div class="type">{{$c.TypeRef}}</div></th>
+{{- end}}
+{{- range .Annotations}}
+<th class="annotation">{{.}}</th>
+{{- end}}
+</tr>
+</thead>
+<tbody>
+{{- range .Rows}}
+<tr id="{{.Id}}">
+<td class="rule">{{.Number}}</td>
+{{- range .Inputs}}
+<td class="text">{{.}}</td>
+{{- end}}
+{{- range $i, $e := .Outputs}}
+<td class="text{{if eq $i 0}} output{{end}}">{{$e}}</td>
+{{- end}}
+{{- range .Annotations}}
+<td class="annotation">{{.}}</td>
+{{- end}}
+</tr>
+{{- end}}
+</tbody>
+</table>
+<p>Hit policy: {{.HitPolicy}}</p>
+</body>
+</html>
+`))
+
+// Html writes the DecisionTable as a self-contained HTML document with
+// the given title: the hit policy badge, the input and output headers
+// with their expressions and types, one row per rule and the rule
+// description and annotations.
+func (this *DecisionTable) Html(w io.Writer, title string) (error) {
+	return htmlTemplate.Execute(w, this.view(title))
+}
+
+// ------------------------------------------------------------------------
+// SVG.
+// ------------------------------------------------------------------------
+
+// SVG layout in pixels. Text is set in a monospace font so that column
+// widths can be derived from the number of characters.
+const (
+	svgFontSize = 12
+	svgCharWidth = 8
+	svgLineHeight = 16
+	svgPadding = 6
+	svgMinWidth = 40
+)
+
+// Svg writes the DecisionTable as a self-contained SVG image with the
+// same content as Html.
+func (this *DecisionTable) Svg(w io.Writer, title string) (error) {
+
+	tv := this.view(title)
+
+	// Build the header and body cells in column order.
+
+	header := [][]string{{tv.Badge}}
+
+	for _, c := range append(append([]*columnView{}, tv.Inputs...), tv.Outputs...) {
+		header = append(header, []string{c.Label, c.Text, c.TypeRef})
+	}
+
+	for _, name := range tv.Annotations {
+		header = append(header, []string{name})
+	}
+
+	var body [][]string
+
+	for _, row := range tv.Rows {
+		cells := []string{fmt.Sprint(row.Number)}
+		cells = append(cells, row.Inputs...)
+		cells = append(cells, row.Outputs...)
+		body = append(body, append(cells, row.Annotations...))
+	}
+
+	// Size the columns to their widest cell.
+
+	widths := make([]int, len(header))
+
+	for i, lines := range header {
+		for _, s := range lines {
+			widths[i] = maxInt(widths[i], svgTextWidth(s))
+		}
+	}
+
+	for _, cells := range body {
+		for i, s := range cells {
+			widths[i] = maxInt(widths[i], svgTextWidth(s))
+		}
+	}
+
+	x := make([]int, len(widths) + 1)
+
+	for i, width := range widths {
+		x[i+1] = x[i] + maxInt(width, svgMinWidth)
+	}
+
+	titleHeight := svgLineHeight + 2 * svgPadding
+	headerHeight := 3 * svgLineHeight + 2 * svgPadding
+	rowHeight := svgLineHeight + 2 * svgPadding
+	width := x[len(x)-1] + 1
+	height := titleHeight + headerHeight + len(body) * rowHeight + 1
+	firstOutput := 1 + len(tv.Inputs)
+	firstAnnotation := firstOutput + len(tv.Outputs)
+
+	buf := new(bytes.Buffer)
+
+	fmt.Fprintf(buf, "<svg xmlns=\"http://www.w3.org/2000/svg\" width=\"%d\" height=\"%d\" viewBox=\"0 0 %d %d\" " +
+		"font-family=\"monospace\" font-size=\"%d\">\n", width, height, width, height, svgFontSize)
+	fmt.Fprintf(buf, "<title>%s</title>\n", svgEscape(tv.Title + ` (` + tv.HitPolicy + `)`))
+	fmt.Fprintf(buf, "<rect width=\"%d\" height=\"%d\" fill=\"#fff\"/>\n", width, height)
+	fmt.Fprintf(buf, "<text x=\"%d\" y=\"%d\" font-weight=\"bold\">%s</text>\n",
+		svgPadding, svgPadding + svgFontSize, svgEscape(tv.Title))
+
+	// Header cells: label, expression or name, and type.
+
+	y := titleHeight
+
+	for i, lines := range header {
+
+		fill := `#f4f4f4`
+
+		if i > 0 && i < firstOutput {
+			fill = `#e8f0fa`
+		} else if i == 0 || i >= firstAnnotation {
+			fill = `#fff`
+		}
+
+		fmt.Fprintf(buf, "<rect x=\"%d\" y=\"%d\" width=\"%d\" height=\"%d\" fill=\"%s\" stroke=\"#999\"/>\n",
+			x[i], y, x[i+1] - x[i], headerHeight, fill)
+
+		for j, s := range lines {
+			attrs := ``
+			switch {
+			case i == 0 || j == 0:
+				attrs = ` font-weight="bold"`
+			case j == 2:
+				attrs = ` font-style="italic" fill="#777"`
+			}
+			fmt.Fprintf(buf, "<text x=\"%d\" y=\"%d\"%s>%s</text>\n",
+				x[i] + svgPadding, y + svgPadding + svgFontSize + j * svgLineHeight, attrs, svgEscape(s))
+		}
+	}
+
+	// Rule rows.
+
+	y += headerHeight
+
+	for _, cells := range body {
+
+		for i, s := range cells {
+
+			attrs := ``
+
+			if i == 0 {
+				attrs = ` fill="#777"`
+			} else if i >= firstAnnotation {
+				attrs = ` font-style="italic" fill="#555"`
+			}
+
+			fmt.Fprintf(buf, "<rect x=\"%d\" y=\"%d\" width=\"%d\" height=\"%d\" fill=\"none\" stroke=\"#999\"/>\n",
+				x[i], y, x[i+1] - x[i], rowHeight)
+			fmt.Fprintf(buf, "<text x=\"%d\" y=\"%d\"%s>%s</text>\n",
+				x[i] + svgPadding, y + svgPadding + svgFontSize, attrs, svgEscape(s))
+		}
+
+		y += rowHeight
+	}
+
+	// Separate the inputs from the outputs as the Modeler does.
+
+	fmt.Fprintf(buf, "<line x1=\"%d\" y1=\"%d\" x2=\"%d\" y2=\"%d\" stroke=\"#999\" stroke-width=\"3\"/>\n",
+		x[firstOutput], titleHeight, x[firstOutput], height - 1)
+	fmt.Fprintf(buf, "</svg>\n")
+
+	_, err := w.Write(buf.Bytes())
+	return err
+}
+
+// svgTextWidth returns the width of a cell holding a single line of text.
+func svgTextWidth(s string) (int) {
+	return utf8.RuneCountInString(svgLine(s)) * svgCharWidth + 2 * svgPadding
+}
+
+// svgLine folds multi-line text onto a single line.
+func svgLine(s string) (string) {
+	return strings.TrimSpace(svgFold.Replace(s))
+}
+
+var svgFold = strings.NewReplacer("\r\n", ` `, "\n", ` `, "\r", ` `, "\t", ` `)
+
+// svgEscape folds text onto a single line and escapes it for XML.
+func svgEscape(s string) (string) {
+	buf := new(bytes.Buffer)
+	xml.EscapeText(buf, []byte(svgLine(s)))
+	return buf.String()
+}
+
+func maxInt(a, b int) (int) {
+	if a > b {
+		return a
+	}
+	return b
+}
@@ -0,0 +1,50 @@
+package model
+
+import (
+	`bytes`
+	`strings`
+	`testing`
+)
+
+func TestRenderAnnotations(t *testing.T) {
+
+	dt := testTable(``, ``, `string`, ``, [2]string{`< 5`, `"low"`}, [2]string{`>= 5`, `"high"`})
+	dt.Annotations = []*RuleAnnotationClause{{Name: `Ticket`}}
+	dt.Rules[0].Description = `fall rule`
+	dt.Rules[0].AnnotationEntries = []*AnnotationEntry{{Text: `JIRA-1`}}
+
+	tests := []struct {
+		name	string
+		render	func(*bytes.Buffer) error
+		want	[]string
+	}{
+		{`html`, func(buf *bytes.Buffer) error { return dt.Html(buf, `Dish`) }, []string{
+			`<th class="annotation">Annotation</th>`,
+			`<th class="annotation">Ticket</th>`,
+			`<td class="annotation">fall rule</td>`,
+			`<td class="annotation">JIRA-1</td>`,
+		}},
+		{`svg`, func(buf *bytes.Buffer) error { return dt.Svg(buf, `Dish`) }, []string{
+			`>Annotation</text>`,
+			`>Ticket</text>`,
+			`>fall rule</text>`,
+			`>JIRA-1</text>`,
+		}},
+	}
+
+	for _, tt := range tests {
+
+		buf := new(bytes.Buffer)
+
+		if err := tt.render(buf); err != nil {
+			t.Errorf(`%s: %v`, tt.name, err)
+			continue
+		}
+
+		for _, want := range tt.want {
+			if !strings.Contains(buf.String(), want) {
+				t.Errorf(`%s: output lacks %s`, tt.name, want)
+			}
+		}
+	}
+}
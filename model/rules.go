@@ -30,41 +30,69 @@ type DmnRules interface {
 	Write(io.Writer) (int, error)
 	Headers() ([][]string)
 	Rules() ([][]string)
+	Decision() (*Decision)
 }
 
 type dmnRules struct {
+	decision *Decision
 	headers	[][]string
 	rules	[][]string
 }
 
-func NewDmnRules(dmn *Dmn) (DmnRules, error) {
+func NewDmnRules(d *Decision) (DmnRules, error) {
+
+	if _, err := d.Table(); err != nil {
+		return nil, err
+	}
 
 	// Create shorthand for nested objects to improve readability.
 
-	inputs := dmn.Decision.DecisionTable.Inputs
-	outputs := dmn.Decision.DecisionTable.Outputs
-	rules := dmn.Decision.DecisionTable.Rules
+	inputs := d.DecisionTable.Inputs
+	outputs := d.DecisionTable.Outputs
+	annotations := d.DecisionTable.Annotations
+	rules := d.DecisionTable.Rules
+
+	// Collect the table attributes, each of which gets a trailing column
+	// of its own whose Values header cell holds the attribute value.
+
+	attrs := [][]string{{`Hit Policy`, `hitPolicy`, d.DecisionTable.hitPolicy()}}
+
+	if agg := d.DecisionTable.Aggregation; agg != `` {
+		attrs = append(attrs, []string{`Aggregation`, `aggregation`, agg})
+	}
+
+	if po := d.DecisionTable.PreferredOrientation; po != `` {
+		attrs = append(attrs, []string{`Preferred Orientation`, `preferredOrientation`, po})
+	}
 
 	// Determine number of rows by counting rules. Determine number
-	// of columns by counting input and output entries for the first
-	// rule.
+	// of columns by counting input expressions and outputs, then add
+	// trailing columns for the rule description, any annotations and
+	// the table attributes.
 
 	rows := len(rules)
-	cols := len(rules[0].InputEntries) + len(rules[0].OutputEntries)
+	cols := len(outputs)
+
+	for _, input := range inputs {
+		cols += len(input.InputExpressions)
+	}
+
+	width := cols + 1 + len(annotations) + len(attrs)
 
 	// Create the data structures: [row][col]string.
 
 	table := &dmnRules{
-		headers: make([][]string, 4),
+		decision: d,
+		headers: make([][]string, 7),
 		rules: make([][]string, rows),
 	}
 
 	for i := range table.headers {
-		table.headers[i] = make([]string, cols)
+		table.headers[i] = make([]string, width)
 	}
 
 	for i := range table.rules {
-		table.rules[i] = make([]string, cols)
+		table.rules[i] = make([]string, width)
 	}
 
 	// Populate the data structure.
@@ -79,6 +107,8 @@ func NewDmnRules(dmn *Dmn) (DmnRules, error) {
 			table.headers[1][hcol] = input.Label
 			table.headers[2][hcol] = inputExp.Text
 			table.headers[3][hcol] = inputExp.TypeRef
+			table.headers[4][hcol] = input.InputValues.text()
+			table.headers[6][hcol] = input.Description
 
 			hcol++
 		}
@@ -90,30 +120,75 @@ func NewDmnRules(dmn *Dmn) (DmnRules, error) {
 		table.headers[1][hcol] = output.Label
 		table.headers[2][hcol] = output.Name
 		table.headers[3][hcol] = output.TypeRef
+		table.headers[4][hcol] = output.OutputValues.text()
+		table.headers[5][hcol] = output.DefaultOutputEntry.text()
+		table.headers[6][hcol] = output.Description
+
+		hcol++
+	}
+
+	table.headers[0][hcol] = `Annotation`
+	table.headers[1][hcol] = `Description`
+	table.headers[2][hcol] = `description`
+
+	hcol++
+
+	for _, annotation := range annotations {
+
+		table.headers[0][hcol] = `Annotation`
+		table.headers[1][hcol] = annotation.Name
+		table.headers[2][hcol] = annotation.Name
+		table.headers[6][hcol] = annotation.Description
 
 		hcol++
 	}
 
+	for _, attr := range attrs {
+
+		table.headers[0][hcol] = `Table`
+		table.headers[1][hcol] = attr[0]
+		table.headers[2][hcol] = attr[1]
+		table.headers[4][hcol] = attr[2]
+
+		hcol++
+	}
 
 	for row, rule := range rules {
 
 		ecol := 0
 
 		for _, inputEntry := range rule.InputEntries {
-			table.rules[row][ecol] = inputEntry.Text
+			if ecol < cols - len(outputs) {
+				table.rules[row][ecol] = inputEntry.Text
+			}
 			ecol++
 		}
 
+		ecol = cols - len(outputs)
+
 		for _, outputEntry := range rule.OutputEntries {
-			table.rules[row][ecol] = outputEntry.Text
+			if ecol < cols {
+				table.rules[row][ecol] = outputEntry.Text
+			}
 			ecol++
 		}
+
+		table.rules[row][cols] = rule.Description
+
+		for i, annotationEntry := range rule.AnnotationEntries {
+			if i < len(annotations) {
+				table.rules[row][cols+1+i] = annotationEntry.Text
+			}
+		}
 	}
 
 	table.headers[0] = append([]string{`Flow`}, table.headers[0]...)
 	table.headers[1] = append([]string{`Label`}, table.headers[1]...)
 	table.headers[2] = append([]string{`Name`}, table.headers[2]...)
 	table.headers[3] = append([]string{`Type`}, table.headers[3]...)
+	table.headers[4] = append([]string{`Values`}, table.headers[4]...)
+	table.headers[5] = append([]string{`Default`}, table.headers[5]...)
+	table.headers[6] = append([]string{`Description`}, table.headers[6]...)
 
 	for i, rule := range table.rules {
 		table.rules[i] = append([]string{`Rule`}, rule...)
@@ -162,3 +237,7 @@ func (this *dmnRules) Headers() ([][]string) {
 func (this *dmnRules) Rules() ([][]string) {
 	return this.rules
 }
+
+func (this *dmnRules) Decision() (*Decision) {
+	return this.decision
+}
@@ -0,0 +1,80 @@
+package model
+
+import (
+	`testing`
+)
+
+func TestDmnRulesTableAttributes(t *testing.T) {
+
+	tests := []struct {
+		name	string
+		table	*DecisionTable
+		attrs	map[string]string
+	}{
+		{`default hit policy`, &DecisionTable{},
+			map[string]string{`hitPolicy`: `UNIQUE`}},
+		{`collect sum`, &DecisionTable{HitPolicy: `COLLECT`, Aggregation: `SUM`},
+			map[string]string{`hitPolicy`: `COLLECT`, `aggregation`: `SUM`}},
+		{`orientation`, &DecisionTable{HitPolicy: `FIRST`, PreferredOrientation: `Rule-as-Column`},
+			map[string]string{`hitPolicy`: `FIRST`, `preferredOrientation`: `Rule-as-Column`}},
+	}
+
+	for _, tt := range tests {
+
+		tt.table.Inputs = []*Input{
+			{Label: `Season`, InputExpressions: []*InputExpression{{Text: `season`, TypeRef: `string`}}},
+		}
+		tt.table.Outputs = []*Output{{Label: `Dish`, Name: `dish`, TypeRef: `string`}}
+		tt.table.Rules = []*Rule{{
+			InputEntries: []*InputEntry{{Text: `"Fall"`}},
+			OutputEntries: []*OutputEntry{{Text: `"Stew"`}},
+		}}
+
+		rules, err := NewDmnRules(&Decision{Id: `dish`, DecisionTable: tt.table})
+
+		if err != nil {
+			t.Fatalf(`%s: NewDmnRules: %v`, tt.name, err)
+		}
+
+		headers, rows := rules.Headers(), rules.Rules()
+		width := len(rows[0])
+
+		for i, h := range headers {
+			if len(h) != width {
+				t.Errorf(`%s: header row %d has %d cells, want %d`, tt.name, i, len(h), width)
+			}
+		}
+
+		// Columns are: label, input, output, rule description, then one
+		// per table attribute.
+
+		got := make(map[string]string)
+
+		for c := 0; c < width; c++ {
+
+			if headers[0][c] != `Table` {
+				continue
+			} else if c < 4 {
+				t.Errorf(`%s: table attribute %s in column %d of the rule grid`, tt.name, headers[2][c], c)
+			} else if rows[0][c] != `` {
+				t.Errorf(`%s: rule has %q under table attribute %s`, tt.name, rows[0][c], headers[2][c])
+			}
+
+			got[headers[2][c]] = headers[4][c]
+		}
+
+		if len(got) != len(tt.attrs) {
+			t.Errorf(`%s: table attributes %v, want %v`, tt.name, got, tt.attrs)
+		}
+
+		for name, want := range tt.attrs {
+			if got[name] != want {
+				t.Errorf(`%s: %s = %q, want %q`, tt.name, name, got[name], want)
+			}
+		}
+
+		if rows[0][1] != `"Fall"` || rows[0][2] != `"Stew"` {
+			t.Errorf(`%s: rule row %v`, tt.name, rows[0])
+		}
+	}
+}
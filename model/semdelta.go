@@ -0,0 +1,614 @@
+// Copyright 2018 John Scherff
+//
+// Licensed under the Apache License, version 2.0 (the "License");
+// you may not use this file except in compliance with the License.
+// You may obtain a copy of the License at
+//
+//     http://www.apache.org/licenses/LICENSE-2.0
+//
+// Unless required by applicable law or agreed to in writing, software
+// distributed under the License is distributed on an "AS IS" BASIS,
+// WITHOUT WARRANTIES OR CONDITIONS OF ANY KIND, either express or implied.
+// See the License for the specific language governing permissions and
+// limitations under the License.
+
+package model
+
+import (
+	`fmt`
+	`sort`
+	`strings`
+	`github.com/jscherff/dmnsdk/feel`
+)
+
+const (
+	ChangeAdded     = `ADDED`
+	ChangeRemoved   = `REMOVED`
+	ChangeModified  = `MODIFIED`
+	ChangeReordered = `REORDERED`
+)
+
+// ------------------------------------------------------------------------
+// SemanticChange.
+// ------------------------------------------------------------------------
+
+// SemanticChange describes one difference in the logic of two decisions.
+// Element is the kind of element that changed (decision, decisionTable,
+// input, output, annotation, rule, or the literalExpression, context,
+// invocation or relation of a decision without a table), Key identifies
+// it by content, Id1 and Id2 are its ids in the first and second DMN, and
+// Detail describes the change.
+// Decision is the id of the decision the change belongs to when the DMNs
+// compared have more than one decision.
+type SemanticChange struct {
+	Decision		string
+	Change			string
+	Element			string
+	Key			string
+	Id1			string
+	Id2			string
+	Detail			string
+}
+
+// String implements the Stringer interface for SemanticChange.
+func (this *SemanticChange) String() (string) {
+
+	s := fmt.Sprintf(`%s %s %s`, this.Change, this.Element, this.Key)
+
+	if this.Decision != `` {
+		s = fmt.Sprintf(`%s: %s`, this.Decision, s)
+	}
+
+	if this.Detail != `` {
+		s += `: ` + this.Detail
+	}
+
+	return s
+}
+
+// ------------------------------------------------------------------------
+// SemanticDelta.
+// ------------------------------------------------------------------------
+
+// SemanticDelta lists the differences in logic between two decisions,
+// ignoring element ids.
+type SemanticDelta []*SemanticChange
+
+// NewSemanticDelta compares the decisions of two DMNs by content.
+// Decisions are aligned by id; a decision present in only one DMN is
+// reported as an added or removed decision. See Decision.SemanticDelta.
+func NewSemanticDelta(dmn1, dmn2 *Dmn) (SemanticDelta, error) {
+
+	if len(dmn1.Decisions) == 0 || len(dmn2.Decisions) == 0 {
+		return nil, fmt.Errorf(`dmn has no decisions`)
+	}
+
+	// Name the decision of each change only when there is a choice.
+
+	multi := len(dmn1.Decisions) > 1 || len(dmn2.Decisions) > 1
+
+	var delta SemanticDelta
+
+	add := func(d *Decision, changes ...*SemanticChange) {
+		for _, change := range changes {
+			if multi {
+				change.Decision = d.Id
+			}
+			delta = append(delta, change)
+		}
+	}
+
+	for _, d1 := range dmn1.Decisions {
+		if d2, err := dmn2.Decision(d1.Id); err != nil {
+			add(d1, &SemanticChange{Change: ChangeRemoved, Element: `decision`, Key: d1.Id, Id1: d1.Id})
+		} else {
+			add(d1, d1.SemanticDelta(d2)...)
+		}
+	}
+
+	for _, d2 := range dmn2.Decisions {
+		if _, err := dmn1.Decision(d2.Id); err != nil {
+			add(d2, &SemanticChange{Change: ChangeAdded, Element: `decision`, Key: d2.Id, Id2: d2.Id})
+		}
+	}
+
+	return delta, nil
+}
+
+// SemanticDelta compares the decision logic of the Decision with that of
+// another by content. Decision tables are compared column by column and
+// rule by rule: inputs are aligned by input expression text, outputs by
+// output name and annotation columns by name, so ids regenerated by the
+// modeler do not register as changes, and rules are aligned by their
+// entry values and reported as added, removed, modified (same input
+// entries or same id, different entries or annotations) or reordered.
+// Other decision logic is compared by its expression text, and a change
+// of the kind of logic is reported as a modified decision.
+func (this *Decision) SemanticDelta(other *Decision) (SemanticDelta) {
+
+	l1, l2 := this.Logic(), other.Logic()
+
+	if l1 != l2 {
+		return SemanticDelta{{
+			Change: ChangeModified, Element: `decision`, Key: `logic`, Id1: this.Id, Id2: other.Id,
+			Detail: fmt.Sprintf(`%s -> %s`, display(l1), display(l2)),
+		}}
+	} else if l1 == `decisionTable` {
+		return this.DecisionTable.SemanticDelta(other.DecisionTable)
+	}
+
+	t1 := boxedText(this.LiteralExpression, this.Context, this.Invocation, this.Relation, nil)
+	t2 := boxedText(other.LiteralExpression, other.Context, other.Invocation, other.Relation, nil)
+
+	if t1 == t2 {
+		return nil
+	}
+
+	return SemanticDelta{{
+		Change: ChangeModified, Element: l1, Key: `expression`, Id1: this.Id, Id2: other.Id,
+		Detail: fmt.Sprintf(`%s -> %s`, display(t1), display(t2)),
+	}}
+}
+
+// SemanticDelta compares the DecisionTable with another by content.
+func (this *DecisionTable) SemanticDelta(other *DecisionTable) (SemanticDelta) {
+
+	var delta SemanticDelta
+
+	add := func(change, element, key, id1, id2, detail string) {
+		delta = append(delta, &SemanticChange{
+			Change: change, Element: element, Key: key, Id1: id1, Id2: id2, Detail: detail,
+		})
+	}
+
+	if hp1, hp2 := this.hitPolicy(), other.hitPolicy(); hp1 != hp2 {
+		add(ChangeModified, `decisionTable`, `hitPolicy`, this.Id, other.Id,
+			fmt.Sprintf(`%s -> %s`, hp1, hp2))
+	}
+
+	if this.Aggregation != other.Aggregation {
+		add(ChangeModified, `decisionTable`, `aggregation`, this.Id, other.Id,
+			fmt.Sprintf(`%s -> %s`, this.Aggregation, other.Aggregation))
+	}
+
+	// Align input and output columns by content.
+
+	in1, in2 := inputColumns(this), inputColumns(other)
+	out1, out2 := outputColumns(this), outputColumns(other)
+
+	ann1, ann2 := annotationColumns(this), annotationColumns(other)
+
+	inPairs := alignColumns(`input`, in1, in2, add)
+	outPairs := alignColumns(`output`, out1, out2, add)
+	annPairs := alignColumns(`annotation`, ann1, ann2, add)
+
+	// Build the entry values of each rule over the common columns.
+
+	cells := func(dt *DecisionTable, side int) (rows [][]string) {
+		for _, rule := range dt.Rules {
+			var row []string
+			for _, p := range inPairs {
+				row = append(row, cellText(rule.InputEntries, p[side], true))
+			}
+			for _, p := range outPairs {
+				row = append(row, cellText(rule.OutputEntries, p[side], false))
+			}
+			for _, p := range annPairs {
+				row = append(row, annotationText(rule, p[side]))
+			}
+			rows = append(rows, row)
+		}
+		return rows
+	}
+
+	rows1, rows2 := cells(this, 0), cells(other, 1)
+	ninputs, nentries := len(inPairs), len(inPairs) + len(outPairs)
+
+	var headers []string
+
+	for _, p := range inPairs {
+		headers = append(headers, `input ` + in1[p[0]].key)
+	}
+	for _, p := range outPairs {
+		headers = append(headers, `output ` + out1[p[0]].key)
+	}
+	for _, p := range annPairs {
+		headers = append(headers, `annotation ` + ann1[p[0]].key)
+	}
+
+	// Pair rules with identical entries, then rules with identical input
+	// entries, then rules with the same id. Annotations do not pair rules
+	// but are reported as modifications of paired rules.
+
+	match1 := make([]int, len(rows1))
+	match2 := make([]int, len(rows2))
+
+	for i := range match1 {
+		match1[i] = -1
+	}
+	for j := range match2 {
+		match2[j] = -1
+	}
+
+	pairBy := func(key func([]string) string, ok func(i, j int) bool) {
+		index := make(map[string][]int)
+		for j, row := range rows2 {
+			if match2[j] < 0 {
+				k := key(row)
+				index[k] = append(index[k], j)
+			}
+		}
+		for i, row := range rows1 {
+			if match1[i] >= 0 {
+				continue
+			}
+			k := key(row)
+			for n, j := range index[k] {
+				if ok(i, j) {
+					match1[i], match2[j] = j, i
+					index[k] = append(index[k][:n], index[k][n+1:]...)
+					break
+				}
+			}
+		}
+	}
+
+	always := func(i, j int) bool { return true }
+
+	pairBy(func(row []string) string { return strings.Join(row[:nentries], "\x00") }, always)
+	pairBy(func(row []string) string { return strings.Join(row[:ninputs], "\x00") }, always)
+	pairBy(func(row []string) string { return `` }, func(i, j int) bool {
+		return this.Rules[i].Id == other.Rules[j].Id
+	})
+
+	// Report modified rules and rules that changed position relative to
+	// the other matched rules.
+
+	inOrder := longestIncreasing(match1)
+
+	for i, j := range match1 {
+
+		if j < 0 {
+			continue
+		}
+
+		key := fmt.Sprintf(`%d -> %d`, i+1, j+1)
+		id1, id2 := this.Rules[i].Id, other.Rules[j].Id
+
+		var diffs []string
+
+		for c := range rows1[i] {
+			if rows1[i][c] != rows2[j][c] {
+				diffs = append(diffs, fmt.Sprintf(`%s: %s -> %s`,
+					headers[c], display(rows1[i][c]), display(rows2[j][c])))
+			}
+		}
+
+		if len(diffs) > 0 {
+			add(ChangeModified, `rule`, key, id1, id2, strings.Join(diffs, `; `))
+		} else if !inOrder[i] {
+			add(ChangeReordered, `rule`, key, id1, id2, ``)
+		}
+	}
+
+	for i, j := range match1 {
+		if j < 0 {
+			add(ChangeRemoved, `rule`, fmt.Sprintf(`%d`, i+1), this.Rules[i].Id, ``,
+				ruleText(headers, rows1[i]))
+		}
+	}
+
+	for j, i := range match2 {
+		if i < 0 {
+			add(ChangeAdded, `rule`, fmt.Sprintf(`%d`, j+1), ``, other.Rules[j].Id,
+				ruleText(headers, rows2[j]))
+		}
+	}
+
+	return delta
+}
+
+// ------------------------------------------------------------------------
+// Semantic Delta Helpers.
+// ------------------------------------------------------------------------
+
+// column is an input or output of a decision table identified by content.
+type column struct {
+	key			string
+	id			string
+	attrs			string
+}
+
+// inputColumns identifies each input expression by its text, falling back
+// to the input label when the expression is empty.
+func inputColumns(dt *DecisionTable) (cols []column) {
+
+	for _, input := range dt.Inputs {
+		for _, exp := range input.InputExpressions {
+
+			key := strings.TrimSpace(exp.Text)
+
+			if key == `` {
+				key = input.Label
+			}
+
+			cols = append(cols, column{key, input.Id,
+				fmt.Sprintf(`label=%q typeRef=%q inputValues=%q description=%q`,
+					input.Label, exp.TypeRef, input.InputValues.text(), input.Description)})
+		}
+	}
+
+	return cols
+}
+
+// outputColumns identifies each output by its name, falling back to the
+// output label when the name is empty.
+func outputColumns(dt *DecisionTable) (cols []column) {
+
+	for _, output := range dt.Outputs {
+
+		key := output.Name
+
+		if key == `` {
+			key = output.Label
+		}
+
+		cols = append(cols, column{key, output.Id,
+			fmt.Sprintf(`label=%q typeRef=%q outputValues=%q defaultOutputEntry=%q description=%q`,
+				output.Label, output.TypeRef, output.OutputValues.text(),
+				output.DefaultOutputEntry.text(), output.Description)})
+	}
+
+	return cols
+}
+
+// annotationColumns identifies the description of the rules by the name
+// description and each annotation column by its name.
+func annotationColumns(dt *DecisionTable) (cols []column) {
+
+	cols = append(cols, column{`description`, ``, ``})
+
+	for _, annotation := range dt.Annotations {
+		cols = append(cols, column{annotation.Name, ``,
+			fmt.Sprintf(`description=%q`, annotation.Description)})
+	}
+
+	return cols
+}
+
+// alignColumns pairs columns with the same key, reports added, removed
+// and modified columns, and returns the index pairs of common columns in
+// the order of the first table.
+func alignColumns(element string, cols1, cols2 []column,
+	add func(change, element, key, id1, id2, detail string)) (pairs [][2]int) {
+
+	used := make([]bool, len(cols2))
+
+	for i, c1 := range cols1 {
+
+		found := false
+
+		for j, c2 := range cols2 {
+
+			if used[j] || c1.key != c2.key {
+				continue
+			}
+
+			used[j], found = true, true
+			pairs = append(pairs, [2]int{i, j})
+
+			if c1.attrs != c2.attrs {
+				add(ChangeModified, element, c1.key, c1.id, c2.id,
+					fmt.Sprintf(`%s -> %s`, c1.attrs, c2.attrs))
+			}
+
+			break
+		}
+
+		if !found {
+			add(ChangeRemoved, element, c1.key, c1.id, ``, ``)
+		}
+	}
+
+	for j, c2 := range cols2 {
+		if !used[j] {
+			add(ChangeAdded, element, c2.key, ``, c2.id, ``)
+		}
+	}
+
+	sort.SliceStable(pairs, func(a, b int) bool { return pairs[a][0] < pairs[b][0] })
+
+	return pairs
+}
+
+// cellText returns the canonical FEEL text of an entry so that spacing
+// differences such as "A","B" and "A", "B" compare equal.
+func cellText(entries interface{}, idx int, unary bool) (string) {
+
+	var text string
+
+	switch entries := entries.(type) {
+	case []*InputEntry:
+		if idx < len(entries) {
+			text = entries[idx].Text
+		}
+	case []*OutputEntry:
+		if idx < len(entries) {
+			text = entries[idx].Text
+		}
+	}
+
+	text = strings.TrimSpace(text)
+
+	if unary {
+		if ut, err := feel.ParseUnaryTests(text); err == nil {
+			return ut.String()
+		}
+	} else {
+		return expressionText(text)
+	}
+
+	return text
+}
+
+// expressionText normalizes the text of a FEEL expression, leaving text
+// outside the simple expression language as written.
+func expressionText(text string) (string) {
+
+	text = strings.TrimSpace(text)
+
+	if text != `` {
+		if x, err := feel.ParseExpression(text); err == nil {
+			return x.String()
+		}
+	}
+
+	return text
+}
+
+// annotationText returns the annotation of a rule in the annotation column
+// with the given index: the rule description, then the annotation entries.
+func annotationText(rule *Rule, idx int) (string) {
+
+	if idx == 0 {
+		return strings.TrimSpace(rule.Description)
+	} else if idx <= len(rule.AnnotationEntries) {
+		return strings.TrimSpace(rule.AnnotationEntries[idx-1].Text)
+	}
+
+	return ``
+}
+
+// boxedText renders the one expression that is set among those a
+// decision, context entry or binding can hold as a single line of text
+// that is independent of element ids.
+func boxedText(le *LiteralExpression, c *Context, inv *Invocation, rel *Relation, dt *DecisionTable) (string) {
+
+	switch {
+
+	case le != nil:
+		return expressionText(le.Text)
+
+	case c != nil:
+		entries := make([]string, len(c.ContextEntries))
+		for i, ce := range c.ContextEntries {
+			entries[i] = boxedText(ce.LiteralExpression, ce.Context, ce.Invocation, ce.Relation, ce.DecisionTable)
+			if ce.Variable != nil {
+				entries[i] = ce.Variable.Name + `: ` + entries[i]
+			}
+		}
+		return `{` + strings.Join(entries, `, `) + `}`
+
+	case inv != nil:
+		bindings := make([]string, len(inv.Bindings))
+		for i, b := range inv.Bindings {
+			bindings[i] = fmt.Sprintf(`%s: %s`, variableName(b.Parameter), boxedText(
+				b.LiteralExpression, b.Context, b.Invocation, b.Relation, b.DecisionTable))
+		}
+		return boxedText(inv.LiteralExpression, nil, nil, nil, nil) +
+			`(` + strings.Join(bindings, `, `) + `)`
+
+	case rel != nil:
+		rows := make([]string, len(rel.Rows))
+		for i, row := range rel.Rows {
+			cells := make([]string, len(row.LiteralExpressions))
+			for j, le := range row.LiteralExpressions {
+				name := ``
+				if j < len(rel.Columns) {
+					name = variableName(rel.Columns[j])
+				}
+				cells[j] = fmt.Sprintf(`%s: %s`, name, boxedText(le, nil, nil, nil, nil))
+			}
+			rows[i] = `{` + strings.Join(cells, `, `) + `}`
+		}
+		return `[` + strings.Join(rows, `, `) + `]`
+
+	case dt != nil:
+		rules := make([]string, len(dt.Rules))
+		for i, rule := range dt.Rules {
+			var cells []string
+			for j := range rule.InputEntries {
+				cells = append(cells, cellText(rule.InputEntries, j, true))
+			}
+			for j := range rule.OutputEntries {
+				cells = append(cells, cellText(rule.OutputEntries, j, false))
+			}
+			rules[i] = strings.Join(cells, ` | `)
+		}
+		return fmt.Sprintf(`decisionTable %s [%s]`, dt.hitPolicy(), strings.Join(rules, `; `))
+
+	default:
+		return ``
+	}
+}
+
+// variableName returns the name of an information item, or an empty
+// string if there is none.
+func variableName(ii *InformationItem) (string) {
+	if ii == nil {
+		return ``
+	}
+	return ii.Name
+}
+
+// display renders an empty output entry visibly.
+func display(text string) (string) {
+	if text == `` {
+		return `(empty)`
+	}
+	return text
+}
+
+// ruleText renders the entries of a rule with their column headers.
+func ruleText(headers, row []string) (string) {
+
+	cols := make([]string, len(row))
+
+	for i := range row {
+		cols[i] = fmt.Sprintf(`%s: %s`, headers[i], display(row[i]))
+	}
+
+	return strings.Join(cols, `; `)
+}
+
+// longestIncreasing marks the matched positions that form the longest
+// subsequence of matches in increasing order; matches outside it moved
+// relative to the others. Unmatched positions (-1) are ignored.
+func longestIncreasing(match []int) (map[int]bool) {
+
+	var idx []int
+
+	for i, j := range match {
+		if j >= 0 {
+			idx = append(idx, i)
+		}
+	}
+
+	n := len(idx)
+	length := make([]int, n)
+	prev := make([]int, n)
+	best := -1
+
+	for a := 0; a < n; a++ {
+
+		length[a], prev[a] = 1, -1
+
+		for b := 0; b < a; b++ {
+			if match[idx[b]] < match[idx[a]] && length[b]+1 > length[a] {
+				length[a], prev[a] = length[b]+1, b
+			}
+		}
+
+		if best < 0 || length[a] > length[best] {
+			best = a
+		}
+	}
+
+	in := make(map[int]bool)
+
+	for a := best; a >= 0; a = prev[a] {
+		in[idx[a]] = true
+	}
+
+	return in
+}
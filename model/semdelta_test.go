@@ -0,0 +1,218 @@
+package model
+
+import (
+	`strings`
+	`testing`
+)
+
+// withIds sets the rule ids of a decision table, in order.
+func withIds(dt *DecisionTable, ids ...string) (*DecisionTable) {
+	for i, id := range ids {
+		dt.Rules[i].Id = id
+	}
+	return dt
+}
+
+func TestSemanticDelta(t *testing.T) {
+
+	base := func() (*DecisionTable) {
+		return testTable(`FIRST`, ``, `string`, ``,
+			[2]string{`< 5`, `"low"`}, [2]string{`[5..10]`, `"mid"`}, [2]string{`> 10`, `"high"`})
+	}
+
+	tests := []struct {
+		name	string
+		other	*DecisionTable
+		want	[]string
+	}{
+		{`identical`, base(), nil},
+		{`regenerated ids`,
+			withIds(base(), `a`, `b`, `c`),
+			nil},
+		{`reordered`,
+			testTable(`FIRST`, ``, `string`, ``,
+				[2]string{`[5..10]`, `"mid"`}, [2]string{`< 5`, `"low"`}, [2]string{`> 10`, `"high"`}),
+			[]string{`REORDERED rule 2 -> 1`}},
+		{`output modified`,
+			withIds(testTable(`FIRST`, ``, `string`, ``,
+				[2]string{`< 5`, `"low"`}, [2]string{`[5..10]`, `"medium"`}, [2]string{`> 10`, `"high"`}),
+				`a`, `b`, `c`),
+			[]string{`MODIFIED rule 2 -> 2: output y: "mid" -> "medium"`}},
+		{`input modified with same id`,
+			testTable(`FIRST`, ``, `string`, ``,
+				[2]string{`< 5`, `"low"`}, [2]string{`[5..12]`, `"mid"`}, [2]string{`> 12`, `"high"`}),
+			[]string{
+				`MODIFIED rule 2 -> 2: input x: [5..10] -> [5..12]`,
+				`MODIFIED rule 3 -> 3: input x: > 10 -> > 12`,
+			}},
+		{`input modified with new id`,
+			withIds(testTable(`FIRST`, ``, `string`, ``,
+				[2]string{`< 5`, `"low"`}, [2]string{`[5..12]`, `"mid"`}, [2]string{`> 10`, `"high"`}),
+				`a`, `b`, `c`),
+			[]string{
+				`REMOVED rule 2: input x: [5..10]; output y: "mid"; annotation description: (empty)`,
+				`ADDED rule 2: input x: [5..12]; output y: "mid"; annotation description: (empty)`,
+			}},
+		{`rule added`,
+			testTable(`FIRST`, ``, `string`, ``,
+				[2]string{`< 0`, `"negative"`}, [2]string{`< 5`, `"low"`}, [2]string{`[5..10]`, `"mid"`}, [2]string{`> 10`, `"high"`}),
+			[]string{`ADDED rule 1: input x: < 0; output y: "negative"; annotation description: (empty)`}},
+		{`rule removed`,
+			testTable(`FIRST`, ``, `string`, ``,
+				[2]string{`< 5`, `"low"`}, [2]string{`> 10`, `"high"`}),
+			[]string{`REMOVED rule 2: input x: [5..10]; output y: "mid"; annotation description: (empty)`}},
+		{`hit policy`,
+			testTable(`UNIQUE`, ``, `string`, ``,
+				[2]string{`< 5`, `"low"`}, [2]string{`[5..10]`, `"mid"`}, [2]string{`> 10`, `"high"`}),
+			[]string{`MODIFIED decisionTable hitPolicy: FIRST -> UNIQUE`}},
+	}
+
+	for _, tt := range tests {
+
+		var got []string
+
+		for _, change := range base().SemanticDelta(tt.other) {
+			got = append(got, change.String())
+		}
+
+		if strings.Join(got, "\n") != strings.Join(tt.want, "\n") {
+			t.Errorf("%s: SemanticDelta =\n\t%s\nwant\n\t%s", tt.name,
+				strings.Join(got, "\n\t"), strings.Join(tt.want, "\n\t"))
+		}
+	}
+}
+
+func TestDecisionSemanticDelta(t *testing.T) {
+
+	literal := func(id, text string) (*Decision) {
+		return &Decision{Id: `d`, LiteralExpression: &LiteralExpression{Id: id, Text: text}}
+	}
+
+	context := func(id, text string) (*Decision) {
+		return &Decision{Id: `d`, Context: &Context{Id: id, ContextEntries: []*ContextEntry{
+			{Variable: &InformationItem{Id: id + `v`, Name: `rate`}, LiteralExpression: &LiteralExpression{Text: `0.5`}},
+			{LiteralExpression: &LiteralExpression{Id: id + `r`, Text: text}},
+		}}}
+	}
+
+	invocation := func(id, text string) (*Decision) {
+		return &Decision{Id: `d`, Invocation: &Invocation{Id: id,
+			LiteralExpression: &LiteralExpression{Text: `fee`},
+			Bindings: []*Binding{{
+				Parameter: &InformationItem{Id: id + `p`, Name: `amount`},
+				LiteralExpression: &LiteralExpression{Id: id + `b`, Text: text},
+			}},
+		}}
+	}
+
+	relation := func(id, text string) (*Decision) {
+		return &Decision{Id: `d`, Relation: &Relation{Id: id,
+			Columns: []*InformationItem{{Name: `code`}, {Name: `rate`}},
+			Rows: []*RelationRow{{Id: id + `r`, LiteralExpressions: []*LiteralExpression{{Text: `"A"`}, {Text: text}}}},
+		}}
+	}
+
+	tests := []struct {
+		name	string
+		d1, d2	*Decision
+		want	[]string
+	}{
+		{`literal identical`,
+			literal(`a`, `amount * rate`), literal(`b`, `amount*rate`),
+			nil},
+		{`literal modified`,
+			literal(`a`, `amount * rate`), literal(`a`, `amount * rate * 2`),
+			[]string{`MODIFIED literalExpression expression: (amount * rate) -> ((amount * rate) * 2)`}},
+		{`context identical`,
+			context(`a`, `amount * rate`), context(`b`, `amount * rate`),
+			nil},
+		{`context modified`,
+			context(`a`, `amount * rate`), context(`a`, `amount * rate * 2`),
+			[]string{`MODIFIED context expression: {rate: 0.5, (amount * rate)} -> {rate: 0.5, ((amount * rate) * 2)}`}},
+		{`invocation modified`,
+			invocation(`a`, `amount`), invocation(`b`, `amount + 1`),
+			[]string{`MODIFIED invocation expression: fee(amount: amount) -> fee(amount: (amount + 1))`}},
+		{`relation modified`,
+			relation(`a`, `0.5`), relation(`b`, `0.25`),
+			[]string{`MODIFIED relation expression: [{code: "A", rate: 0.5}] -> [{code: "A", rate: 0.25}]`}},
+		{`logic changed`,
+			literal(`a`, `amount * rate`), context(`a`, `amount * rate`),
+			[]string{`MODIFIED decision logic: literalExpression -> context`}},
+		{`logic removed`,
+			literal(`a`, `amount * rate`), &Decision{Id: `d`},
+			[]string{`MODIFIED decision logic: literalExpression -> (empty)`}},
+	}
+
+	for _, tt := range tests {
+
+		var got []string
+
+		for _, change := range tt.d1.SemanticDelta(tt.d2) {
+			got = append(got, change.String())
+		}
+
+		if strings.Join(got, "\n") != strings.Join(tt.want, "\n") {
+			t.Errorf("%s: SemanticDelta =\n\t%s\nwant\n\t%s", tt.name,
+				strings.Join(got, "\n\t"), strings.Join(tt.want, "\n\t"))
+		}
+	}
+}
+
+func TestNewSemanticDelta(t *testing.T) {
+
+	dmn := func(decisions ...*Decision) (*Dmn) {
+		return &Dmn{Decisions: decisions}
+	}
+
+	fee := func(text string) (*Decision) {
+		return &Decision{Id: `fee`, LiteralExpression: &LiteralExpression{Text: text}}
+	}
+
+	table := func() (*Decision) {
+		return &Decision{Id: `dish`, DecisionTable: testTable(``, ``, `string`, ``, [2]string{`< 5`, `"a"`})}
+	}
+
+	tests := []struct {
+		name		string
+		dmn1, dmn2	*Dmn
+		want		[]string
+	}{
+		{`literal only`,
+			dmn(fee(`amount * rate`)), dmn(fee(`amount * rate * 2`)),
+			[]string{`MODIFIED literalExpression expression: (amount * rate) -> ((amount * rate) * 2)`}},
+		{`identical`,
+			dmn(table(), fee(`amount * rate`)), dmn(table(), fee(`amount * rate`)),
+			nil},
+		{`mixed`,
+			dmn(table(), fee(`amount * rate`)), dmn(table(), fee(`amount`)),
+			[]string{`fee: MODIFIED literalExpression expression: (amount * rate) -> amount`}},
+		{`added and removed`,
+			dmn(table()), dmn(fee(`amount`)),
+			[]string{`REMOVED decision dish`, `ADDED decision fee`}},
+	}
+
+	for _, tt := range tests {
+
+		delta, err := NewSemanticDelta(tt.dmn1, tt.dmn2)
+
+		if err != nil {
+			t.Errorf(`%s: NewSemanticDelta: %v`, tt.name, err)
+			continue
+		}
+
+		var got []string
+
+		for _, change := range delta {
+			got = append(got, change.String())
+		}
+
+		if strings.Join(got, "\n") != strings.Join(tt.want, "\n") {
+			t.Errorf("%s: NewSemanticDelta =\n\t%s\nwant\n\t%s", tt.name,
+				strings.Join(got, "\n\t"), strings.Join(tt.want, "\n\t"))
+		}
+	}
+
+	if _, err := NewSemanticDelta(dmn(), dmn(fee(`amount`))); err == nil {
+		t.Errorf(`NewSemanticDelta of a DMN without decisions succeeded`)
+	}
+}
@@ -0,0 +1,95 @@
+// Copyright 2018 John Scherff
+//
+// Licensed under the Apache License, version 2.0 (the "License");
+// you may not use this file except in compliance with the License.
+// You may obtain a copy of the License at
+//
+//     http://www.apache.org/licenses/LICENSE-2.0
+//
+// Unless required by applicable law or agreed to in writing, software
+// distributed under the License is distributed on an "AS IS" BASIS,
+// WITHOUT WARRANTIES OR CONDITIONS OF ANY KIND, either express or implied.
+// See the License for the specific language governing permissions and
+// limitations under the License.
+
+package model
+
+import (
+	`encoding/xml`
+	`fmt`
+)
+
+// ==============================================================================
+// See https://docs.camunda.org/manual/7.13/reference/dmn/
+// ==============================================================================
+
+// DMN schema versions.
+const (
+	DmnVersion11 = `1.1`
+	DmnVersion12 = `1.2`
+	DmnVersion13 = `1.3`
+)
+
+// Namespaces of the DMN schema versions and of the languages and diagram
+// interchange formats they reference. DMN 1.2 and 1.3 share the DC and DI
+// namespaces; DMN 1.1 models drawn with the Camunda Modeler keep diagram
+// data in biodi extension elements instead of DMNDI.
+const (
+	NsDmn11   = `http://www.omg.org/spec/DMN/20151101/dmn.xsd`
+	NsDmn12   = `http://www.omg.org/spec/DMN/20180521/MODEL/`
+	NsDmn13   = `https://www.omg.org/spec/DMN/20191111/MODEL/`
+	NsFeel11  = `http://www.omg.org/spec/FEEL/20140401`
+	NsFeel12  = `http://www.omg.org/spec/DMN/20180521/FEEL/`
+	NsFeel13  = `https://www.omg.org/spec/DMN/20191111/FEEL/`
+	NsDmndi12 = `http://www.omg.org/spec/DMN/20180521/DMNDI/`
+	NsDmndi13 = `https://www.omg.org/spec/DMN/20191111/DMNDI/`
+	NsDc      = `http://www.omg.org/spec/DMN/20180521/DC/`
+	NsDi      = `http://www.omg.org/spec/DMN/20180521/DI/`
+	NsBiodi   = `http://bpmn.io/schema/dmn/biodi/1.0`
+)
+
+// dmnVersions maps the namespace of each DMN schema version to the version.
+var dmnVersions = map[string]string{
+	NsDmn11: DmnVersion11,
+	NsDmn12: DmnVersion12,
+	NsDmn13: DmnVersion13,
+}
+
+// DmnVersion returns the DMN schema version of a namespace.
+func DmnVersion(ns string) (string, error) {
+
+	if v, ok := dmnVersions[ns]; ok {
+		return v, nil
+	}
+
+	return ``, fmt.Errorf(`unsupported DMN namespace %q`, ns)
+}
+
+// ------------------------------------------------------------------------
+// Version Methods.
+// ------------------------------------------------------------------------
+
+// Version returns the DMN schema version of the Dmn, detected from the
+// namespace of its definitions element.
+func (this *Dmn) Version() (string, error) {
+	return DmnVersion(this.XMLName.Space)
+}
+
+// UnmarshalXML implements the xml.Unmarshaler interface for Dmn. The root
+// element must be a definitions element in the namespace of DMN 1.1, 1.2
+// or 1.3. The elements the model captures have the same names in all three
+// versions, so they are decoded into the same model; elements a version
+// adds, such as DMNDI diagram data, are ignored. Camunda extension
+// attributes are matched by their own namespace in every version.
+func (this *Dmn) UnmarshalXML(d *xml.Decoder, start xml.StartElement) (error) {
+
+	type dmn Dmn
+
+	if start.Name.Local != `definitions` {
+		return fmt.Errorf(`root element is %s, not definitions`, start.Name.Local)
+	} else if _, err := DmnVersion(start.Name.Space); err != nil {
+		return err
+	}
+
+	return d.DecodeElement((*dmn)(this), &start)
+}
@@ -0,0 +1,271 @@
+// Copyright 2018 John Scherff
+//
+// Licensed under the Apache License, version 2.0 (the "License");
+// you may not use this file except in compliance with the License.
+// You may obtain a copy of the License at
+//
+//     http://www.apache.org/licenses/LICENSE-2.0
+//
+// Unless required by applicable law or agreed to in writing, software
+// distributed under the License is distributed on an "AS IS" BASIS,
+// WITHOUT WARRANTIES OR CONDITIONS OF ANY KIND, either express or implied.
+// See the License for the specific language governing permissions and
+// limitations under the License.
+
+package server
+
+import (
+	`encoding/json`
+	`fmt`
+	`io`
+	`net/http`
+	`strings`
+	`github.com/jscherff/dmnsdk/api`
+	`github.com/jscherff/dmnsdk/model`
+)
+
+// ==============================================================================
+// See https://docs.camunda.org/manual/7.8/reference/rest/decision-definition/
+// ==============================================================================
+
+// Prefix is the path under which the decision definition endpoints are
+// served, matching the Camunda REST API.
+const Prefix = `/engine-rest/decision-definition`
+
+// ------------------------------------------------------------------------
+// Handler.
+// ------------------------------------------------------------------------
+
+// handler serves the decision definition endpoints from a Repository.
+type handler struct {
+	repo			*Repository
+}
+
+// NewHandler returns an http.Handler that serves the list, count, by-id,
+// by-key, by-key-and-tenant, xml, diagram, evaluate and history time to
+// live decision definition endpoints from a Repository. The decision of
+// a definition, the one whose id is the definition key, is evaluated with
+// Dmn.EvaluateDecision, together with the decisions it requires. The
+// list and count endpoints accept the query parameters of api.DmnQuery.
+func NewHandler(repo *Repository) (http.Handler) {
+	return &handler{repo}
+}
+
+// restError is the JSON error body returned by the Camunda REST API.
+type restError struct {
+	Type			string			`json:"type"`
+	Message			string			`json:"message"`
+}
+
+// ServeHTTP implements the http.Handler interface for handler.
+func (this *handler) ServeHTTP(w http.ResponseWriter, r *http.Request) {
+
+	if !strings.HasPrefix(r.URL.Path, Prefix) {
+		this.error(w, http.StatusNotFound, `NotFoundException`,
+			fmt.Sprintf(`no resource at %s`, r.URL.Path))
+		return
+	}
+
+	path := strings.Trim(strings.TrimPrefix(r.URL.Path, Prefix), `/`)
+
+	var parts []string
+
+	if path != `` {
+		parts = strings.Split(path, `/`)
+	}
+
+	method := http.MethodGet
+
+	if len(parts) > 0 {
+		switch parts[len(parts)-1] {
+		case `evaluate`:
+			method = http.MethodPost
+		case `history-time-to-live`:
+			method = http.MethodPut
+		}
+	}
+
+	if r.Method != method {
+		this.error(w, http.StatusMethodNotAllowed, `RestException`,
+			fmt.Sprintf(`method %s not allowed`, r.Method))
+		return
+	}
+
+	switch {
+
+	case len(parts) == 0:
+
+		if q, err := api.ParseDmnQuery(r.URL.Query()); err != nil {
+			this.error(w, http.StatusBadRequest, `InvalidRequestException`, err.Error())
+		} else {
+			this.json(w, q.Filter(this.repo.DmnList()))
+		}
+
+	case len(parts) == 1 && parts[0] == `count`:
+
+		if q, err := api.ParseDmnQuery(r.URL.Query()); err != nil {
+			this.error(w, http.StatusBadRequest, `InvalidRequestException`, err.Error())
+		} else {
+			this.json(w, &model.DmnCount{Count: q.Count(this.repo.DmnList())})
+		}
+
+	case parts[0] == `key` && len(parts) >= 2:
+
+		key, tenantId, sub := parts[1], ``, parts[2:]
+
+		if len(sub) >= 2 && sub[0] == `tenant-id` {
+			tenantId, sub = sub[1], sub[2:]
+		}
+
+		di, ok := this.repo.DmnInfoByKey(key, tenantId)
+
+		if !ok && tenantId == `` {
+			this.error(w, http.StatusNotFound, `InvalidRequestException`, fmt.Sprintf(
+				`No matching decision definition with key: %s and no tenant-id`, key))
+			return
+		} else if !ok {
+			this.error(w, http.StatusNotFound, `InvalidRequestException`, fmt.Sprintf(
+				`No matching decision definition with key: %s and tenant-id: %s`, key, tenantId))
+			return
+		}
+
+		this.serve(w, r, di, sub)
+
+	default:
+
+		di, ok := this.repo.DmnInfoById(parts[0])
+
+		if !ok {
+			this.error(w, http.StatusNotFound, `InvalidRequestException`, fmt.Sprintf(
+				`No matching decision definition with id: %s`, parts[0]))
+			return
+		}
+
+		this.serve(w, r, di, parts[1:])
+	}
+}
+
+// serve writes the definition or, for the xml and diagram sub-resources,
+// its DMN XML or diagram image, evaluates it for the evaluate sub-resource
+// or updates it for the history-time-to-live sub-resource. As in Camunda,
+// a definition without a diagram answers 204 No Content.
+func (this *handler) serve(w http.ResponseWriter, r *http.Request, di *model.DmnInfo, sub []string) {
+
+	switch {
+
+	case len(sub) == 0:
+		this.json(w, di)
+
+	case len(sub) == 1 && sub[0] == `xml`:
+		xml, _ := this.repo.DmnXml(di.Id)
+		this.json(w, &model.DmnXml{Id: di.Id, DmnXml: xml})
+
+	case len(sub) == 1 && sub[0] == `diagram`:
+		if diagram, ok := this.repo.DmnDiagram(di.Id); !ok {
+			w.WriteHeader(http.StatusNoContent)
+		} else {
+			w.Header().Set(`Content-Type`, diagram.ContentType)
+			w.Write(diagram.Data)
+		}
+
+	case len(sub) == 1 && sub[0] == `evaluate`:
+		this.evaluate(w, r, di)
+
+	case len(sub) == 1 && sub[0] == `history-time-to-live`:
+		this.historyTtl(w, r, di)
+
+	default:
+		this.error(w, http.StatusNotFound, `NotFoundException`,
+			fmt.Sprintf(`no resource %s`, strings.Join(sub, `/`)))
+	}
+}
+
+// evaluate evaluates the decision of a definition with the typed variables
+// in the request body and writes the result list as typed variables.
+func (this *handler) evaluate(w http.ResponseWriter, r *http.Request, di *model.DmnInfo) {
+
+	var req struct {
+		Variables		map[string]*api.Variable	`json:"variables"`
+	}
+
+	dec := json.NewDecoder(r.Body)
+	dec.UseNumber()
+
+	if err := dec.Decode(&req); err != nil && err != io.EOF {
+		this.error(w, http.StatusBadRequest, `InvalidRequestException`, err.Error())
+		return
+	}
+
+	vars, err := api.GoValues(req.Variables)
+
+	if err != nil {
+		this.error(w, http.StatusBadRequest, `InvalidRequestException`, err.Error())
+		return
+	}
+
+	xml, _ := this.repo.DmnXml(di.Id)
+
+	var dr *model.DecisionResult
+
+	if dmn, err := model.NewDmn(xml); err != nil {
+		this.error(w, http.StatusInternalServerError, `RestException`, err.Error())
+		return
+	} else if dr, err = dmn.EvaluateDecision(di.Key, vars); err != nil {
+		this.error(w, http.StatusInternalServerError, `RestException`,
+			fmt.Sprintf(`Cannot evaluate decision %s: %v`, di.Id, err))
+		return
+	}
+
+	results := make([]map[string]*api.Variable, len(dr.Entries))
+
+	for i, entry := range dr.Entries {
+
+		results[i] = make(map[string]*api.Variable)
+
+		for name, val := range entry {
+			if v, err := api.NewVariable(val); err != nil {
+				this.error(w, http.StatusInternalServerError, `RestException`,
+					fmt.Sprintf(`output %s: %v`, name, err))
+				return
+			} else {
+				results[i][name] = v
+			}
+		}
+	}
+
+	this.json(w, results)
+}
+
+// historyTtl sets the history time to live of a definition to the value
+// in the request body.
+func (this *handler) historyTtl(w http.ResponseWriter, r *http.Request, di *model.DmnInfo) {
+
+	var req struct {
+		HistoryTtl		*int			`json:"historyTimeToLive"`
+	}
+
+	if err := json.NewDecoder(r.Body).Decode(&req); err != nil {
+		this.error(w, http.StatusBadRequest, `InvalidRequestException`, err.Error())
+		return
+	} else if req.HistoryTtl != nil && *req.HistoryTtl < 0 {
+		this.error(w, http.StatusBadRequest, `InvalidRequestException`,
+			`History time to live cannot be negative.`)
+		return
+	}
+
+	this.repo.SetHistoryTtl(di.Id, req.HistoryTtl)
+	w.WriteHeader(http.StatusNoContent)
+}
+
+// json writes a value as a JSON response.
+func (this *handler) json(w http.ResponseWriter, v interface{}) {
+	w.Header().Set(`Content-Type`, `application/json`)
+	json.NewEncoder(w).Encode(v)
+}
+
+// error writes a Camunda-style JSON error response.
+func (this *handler) error(w http.ResponseWriter, status int, typ, msg string) {
+	w.Header().Set(`Content-Type`, `application/json`)
+	w.WriteHeader(status)
+	json.NewEncoder(w).Encode(&restError{typ, msg})
+}
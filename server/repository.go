@@ -0,0 +1,381 @@
+// Copyright 2018 John Scherff
+//
+// Licensed under the Apache License, version 2.0 (the "License");
+// you may not use this file except in compliance with the License.
+// You may obtain a copy of the License at
+//
+//     http://www.apache.org/licenses/LICENSE-2.0
+//
+// Unless required by applicable law or agreed to in writing, software
+// distributed under the License is distributed on an "AS IS" BASIS,
+// WITHOUT WARRANTIES OR CONDITIONS OF ANY KIND, either express or implied.
+// See the License for the specific language governing permissions and
+// limitations under the License.
+
+// Package server is a stand-in for the decision definition endpoints of
+// the Camunda REST API. It serves DMN files from a directory so that the
+// api package and the utilities built on it can run without an engine,
+// for example behind an httptest.Server.
+package server
+
+import (
+	`bytes`
+	`crypto/sha1`
+	`fmt`
+	`io/ioutil`
+	`mime`
+	`os`
+	`path/filepath`
+	`sort`
+	`strings`
+	`sync`
+	`github.com/jscherff/dmnsdk/model`
+)
+
+// ------------------------------------------------------------------------
+// Repository.
+// ------------------------------------------------------------------------
+
+// Repository holds deployed decision definitions and their DMN XML.
+type Repository struct {
+	mutex			sync.RWMutex
+	dmnList			model.DmnList
+	dmnXml			map[string]string
+	diagrams		map[string]*model.DmnDiagram
+}
+
+// diagramExts are the file name extensions of diagram images, in the
+// order the engine looks for them.
+var diagramExts = []string{`.png`, `.jpg`, `.gif`, `.svg`}
+
+// NewRepository creates a Repository and deploys every .dmn and .xml file
+// in a directory, in file name order, one deployment per file. Files in
+// a subdirectory are deployed for the tenant named by the subdirectory.
+// As in Camunda, an image file with the base name of a DMN file, such as
+// roles.png for roles.dmn, is deployed as the diagram of its decisions.
+func NewRepository(dir string) (*Repository, error) {
+
+	this := &Repository{
+		dmnXml: make(map[string]string),
+		diagrams: make(map[string]*model.DmnDiagram),
+	}
+
+	if err := this.deployDir(dir, ``); err != nil {
+		return nil, err
+	}
+
+	files, err := ioutil.ReadDir(dir)
+
+	if err != nil {
+		return nil, err
+	}
+
+	for _, fi := range files {
+		if fi.IsDir() {
+			if err := this.deployDir(filepath.Join(dir, fi.Name()), fi.Name()); err != nil {
+				return nil, err
+			}
+		}
+	}
+
+	return this, nil
+}
+
+// deployDir deploys the DMN files in a directory for a tenant.
+func (this *Repository) deployDir(dir, tenantId string) (error) {
+
+	files, err := ioutil.ReadDir(dir)
+
+	if err != nil {
+		return err
+	}
+
+	var names []string
+
+	for _, fi := range files {
+		switch strings.ToLower(filepath.Ext(fi.Name())) {
+		case `.dmn`, `.xml`:
+			if !fi.IsDir() {
+				names = append(names, fi.Name())
+			}
+		}
+	}
+
+	sort.Strings(names)
+
+	for _, name := range names {
+
+		diagram, err := findDiagram(dir, name)
+
+		if err != nil {
+			return err
+		}
+
+		if b, err := ioutil.ReadFile(filepath.Join(dir, name)); err != nil {
+			return err
+		} else if err := this.deploy(tenantId, name, b, diagram); err != nil {
+			return fmt.Errorf(`%s: %v`, filepath.Join(dir, name), err)
+		}
+	}
+
+	return nil
+}
+
+// findDiagram returns the diagram image deployed with a DMN file, if any.
+func findDiagram(dir, name string) (*model.DmnDiagram, error) {
+
+	base := strings.TrimSuffix(name, filepath.Ext(name))
+
+	for _, ext := range diagramExts {
+		if b, err := ioutil.ReadFile(filepath.Join(dir, base + ext)); os.IsNotExist(err) {
+			continue
+		} else if err != nil {
+			return nil, err
+		} else {
+			return &model.DmnDiagram{ContentType: mime.TypeByExtension(ext), Data: b}, nil
+		}
+	}
+
+	return nil, nil
+}
+
+// Deploy adds the decisions in a DMN resource as decision definitions,
+// one per decision. As in Camunda, the key of a definition is the id of
+// its decision and each deployment of a key increments its version,
+// unless the resource is identical to the latest version, in which case
+// it is skipped. Ids have the Camunda form <key>:<version>:<deployment
+// id>, where the deployment id is derived from the resource name and
+// content so that reloading the same directory assigns the same ids.
+// The version tag and history time to live of a definition are taken
+// from the camunda:versionTag and camunda:historyTimeToLive attributes
+// of its decision.
+func (this *Repository) Deploy(resource string, b []byte) (error) {
+	return this.DeployTenant(``, resource, b)
+}
+
+// DeployTenant deploys a DMN resource for a tenant. Keys and versions are
+// independent per tenant; an empty tenant id deploys for no tenant.
+func (this *Repository) DeployTenant(tenantId, resource string, b []byte) (error) {
+	return this.deploy(tenantId, resource, b, nil)
+}
+
+// deploy deploys a DMN resource and its diagram, if any, for a tenant.
+func (this *Repository) deploy(tenantId, resource string, b []byte, diagram *model.DmnDiagram) (error) {
+
+	dmn, err := model.NewDmn(bytes.NewReader(b))
+
+	if err != nil {
+		return err
+	} else if len(dmn.Decisions) == 0 {
+		return fmt.Errorf(`no decision found`)
+	} else if drg, err := dmn.Drg(); err != nil {
+		return err
+	} else if err := drg.Validate(); err != nil {
+		return err
+	}
+
+	this.mutex.Lock()
+	defer this.mutex.Unlock()
+
+	xml := string(b)
+	deploymentId := nameUuid(resource, b)
+
+	if tenantId != `` {
+		deploymentId = nameUuid(tenantId + `/` + resource, b)
+	}
+
+	// As in Camunda, a resource with more than one decision also deploys
+	// a decision requirements definition, keyed by the definitions id and
+	// versioned separately from its decisions.
+
+	var drdId string
+
+	if len(dmn.Decisions) > 1 {
+		drdId = fmt.Sprintf(`%s:%d:%s`, dmn.Id, this.drdVersion(dmn.Id, tenantId) + 1, deploymentId)
+	}
+
+	for _, d := range dmn.Decisions {
+
+		key := d.Id
+		latest := this.latest(key, tenantId)
+
+		if latest != nil && this.dmnXml[latest.Id] == xml {
+			continue
+		}
+
+		version := 1
+
+		if latest != nil {
+			version = latest.Version + 1
+		}
+
+		// A history time to live that is not a number of days, such
+		// as an unresolved placeholder, deploys as unset.
+
+		ttl, _ := d.HistoryTtl()
+
+		di := &model.DmnInfo{
+			Id: fmt.Sprintf(`%s:%d:%s`, key, version, deploymentId),
+			Key: key,
+			Category: dmn.Namespace,
+			Name: d.Name,
+			Version: version,
+			Resource: resource,
+			DeploymentId: deploymentId,
+			TenantId: tenantId,
+			VersionTag: d.VersionTag,
+			HistoryTtl: ttl,
+		}
+
+		if drdId != `` {
+			di.DecisionReqDefId = drdId
+			di.DecisionReqDefKey = dmn.Id
+		}
+
+		this.dmnList = append(this.dmnList, di)
+		this.dmnXml[di.Id] = xml
+
+		if diagram != nil {
+			this.diagrams[di.Id] = diagram
+		}
+	}
+
+	return nil
+}
+
+// DmnList returns a copy of the list of deployed definitions.
+func (this *Repository) DmnList() (model.DmnList) {
+
+	this.mutex.RLock()
+	defer this.mutex.RUnlock()
+
+	dl := make(model.DmnList, len(this.dmnList))
+
+	for i, di := range this.dmnList {
+		dup := *di
+		dl[i] = &dup
+	}
+
+	return dl
+}
+
+// DmnInfoById returns the definition with the given id.
+func (this *Repository) DmnInfoById(id string) (*model.DmnInfo, bool) {
+
+	this.mutex.RLock()
+	defer this.mutex.RUnlock()
+
+	for _, di := range this.dmnList {
+		if di.Id == id {
+			dup := *di
+			return &dup, true
+		}
+	}
+
+	return nil, false
+}
+
+// DmnInfoByKey returns the latest version of the definition with the
+// given key and tenant id; an empty tenant id selects definitions that
+// belong to no tenant.
+func (this *Repository) DmnInfoByKey(key, tenantId string) (*model.DmnInfo, bool) {
+
+	this.mutex.RLock()
+	defer this.mutex.RUnlock()
+
+	if di := this.latest(key, tenantId); di != nil {
+		dup := *di
+		return &dup, true
+	}
+
+	return nil, false
+}
+
+// DmnXml returns the DMN XML of the definition with the given id.
+func (this *Repository) DmnXml(id string) (string, bool) {
+
+	this.mutex.RLock()
+	defer this.mutex.RUnlock()
+
+	xml, ok := this.dmnXml[id]
+	return xml, ok
+}
+
+// SetHistoryTtl sets the history time to live, in days, of the definition
+// with the given id; nil removes it. It reports whether the definition
+// exists.
+func (this *Repository) SetHistoryTtl(id string, ttl *int) (bool) {
+
+	this.mutex.Lock()
+	defer this.mutex.Unlock()
+
+	for _, di := range this.dmnList {
+		if di.Id == id {
+			if ttl != nil {
+				days := *ttl
+				ttl = &days
+			}
+			di.HistoryTtl = ttl
+			return true
+		}
+	}
+
+	return false
+}
+
+// DmnDiagram returns the diagram of the definition with the given id, if
+// one was deployed.
+func (this *Repository) DmnDiagram(id string) (*model.DmnDiagram, bool) {
+
+	this.mutex.RLock()
+	defer this.mutex.RUnlock()
+
+	diagram, ok := this.diagrams[id]
+	return diagram, ok
+}
+
+// latest returns the highest version of a key within a tenant. The
+// caller must hold the mutex.
+func (this *Repository) latest(key, tenantId string) (latest *model.DmnInfo) {
+
+	for _, di := range this.dmnList {
+		if di.Key == key && di.TenantId == tenantId {
+			if latest == nil || di.Version > latest.Version {
+				latest = di
+			}
+		}
+	}
+
+	return latest
+}
+
+// drdVersion returns the highest version of a decision requirements
+// definition key within a tenant, or zero. The caller must hold the mutex.
+func (this *Repository) drdVersion(key, tenantId string) (version int) {
+
+	seen := make(map[string]bool)
+
+	for _, di := range this.dmnList {
+		if di.DecisionReqDefKey == key && di.TenantId == tenantId && !seen[di.DecisionReqDefId] {
+			seen[di.DecisionReqDefId] = true
+			version++
+		}
+	}
+
+	return version
+}
+
+// nameUuid derives a version 5 style UUID from a resource name and content.
+func nameUuid(name string, b []byte) (string) {
+
+	h := sha1.New()
+	h.Write([]byte(name))
+	h.Write([]byte{0})
+	h.Write(b)
+
+	u := h.Sum(nil)[:16]
+	u[6] = (u[6] & 0x0f) | 0x50
+	u[8] = (u[8] & 0x3f) | 0x80
+
+	return fmt.Sprintf(`%x-%x-%x-%x-%x`, u[0:4], u[4:6], u[6:8], u[8:10], u[10:16])
+}
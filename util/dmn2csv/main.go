@@ -15,12 +15,14 @@
 package main
 
 import (
+	`encoding/csv`
 	`flag`
 	`fmt`
 	`log`
 	`io`
 	`os`
 	`github.com/jscherff/dmnsdk/api`
+	`github.com/jscherff/dmnsdk/util/internal/apiflag`
 	`github.com/jscherff/dmnsdk/model`
 )
 
@@ -36,6 +38,7 @@ var (
 	fDmnKey = flag.String(`key`, ``, "Retrieve DMN with key `<key>`")
 	fDmnVer = flag.Int(`ver`, 0, "Retrieve DMN version `<ver>` (requires -key)")
 	fCsvFile = flag.String(`file`, ``, "Store results in file `<file>`")
+	fDecision = flag.String(`decision`, ``, "Convert only the decision with ID `<id>` (default all)")
 )
 
 func init() {
@@ -71,25 +74,39 @@ func main() {
 
 	var (
 		dmn *model.Dmn
-		rules model.DmnRules
+		rules []model.DmnRules
 		out io.WriteCloser
 	)
 
-	api := api.NewDmnApi(*fSvcUrl)
+	opts, err := apiflag.Options()
+
+	if err != nil {
+		log.Fatal(err)
+	}
+
+	api := api.NewDmnApi(*fSvcUrl, opts...)
 
 	switch {
 	case set[`ver`]:
-		dmn, err = api.DmnByKeyVer(*fDmnKey, *fDmnVer)
+		dmn, err = api.DmnByKeyVerTenant(*fDmnKey, *fDmnVer, apiflag.Tenant())
 	case set[`id`]:
 		dmn, err = api.DmnById(*fDmnId)
 	case set [`key`]:
-		dmn, err = api.DmnByKey(*fDmnKey)
+		dmn, err = api.DmnByKeyTenant(*fDmnKey, apiflag.Tenant())
 	}
 
 	if err != nil {
 		log.Fatal(err)
-	} else if rules, err = dmn.Rules(); err != nil {
+	} else if set[`decision`] {
+		rules, err = dmn.Rules(*fDecision)
+	} else {
+		rules, err = dmn.Rules()
+	}
+
+	if err != nil {
 		log.Fatal(err)
+	} else if len(rules) == 0 {
+		log.Fatal(`DMN has no decision table`)
 	}
 
 	if set[`file`] {
@@ -101,7 +118,28 @@ func main() {
 		out = os.Stdout
 	}
 
-	if _, err := rules.Write(out); err != nil {
-		log.Fatal(err)
+	// Precede each table with the decision it belongs to when there is
+	// more than one.
+
+	for _, r := range rules {
+
+		if len(rules) > 1 {
+			d := r.Decision()
+			if err := writeRow(out, `Decision`, d.Id, d.Name); err != nil {
+				log.Fatal(err)
+			}
+		}
+
+		if _, err := r.Write(out); err != nil {
+			log.Fatal(err)
+		}
 	}
 }
+
+// writeRow writes a single CSV row.
+func writeRow(w io.Writer, row ...string) (error) {
+	cw := csv.NewWriter(w)
+	cw.Write(row)
+	cw.Flush()
+	return cw.Error()
+}
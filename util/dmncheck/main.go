@@ -0,0 +1,236 @@
+// Copyright 2018 John Scherff
+//
+// Licensed under the Apache License, Version 2.0 (the "License");
+// you may not use this file except in compliance with the License.
+// You may obtain a copy of the License at
+//
+//     http://www.apache.org/licenses/LICENSE-2.0
+//
+// Unless required by applicable law or agreed to in writing, software
+// distributed under the License is distributed on an "AS IS" BASIS,
+// WITHOUT WARRANTIES OR CONDITIONS OF ANY KIND, either express or implied.
+// See the License for the specific language governing permissions and
+// limitations under the License.
+
+package main
+
+import (
+	`encoding/csv`
+	`flag`
+	`fmt`
+	`log`
+	`io`
+	`os`
+	`github.com/jscherff/dmnsdk/analysis`
+	`github.com/jscherff/dmnsdk/api`
+	`github.com/jscherff/dmnsdk/util/internal/apiflag`
+	`github.com/jscherff/dmnsdk/model`
+)
+
+var (
+	fSvcUrl = flag.String(`url`, ``, "Use service at `http[s]://<hostname>[:<port>]`")
+	fDmnId = flag.String(`id`, ``, "Check DMN with ID `<id>`")
+	fDmnKey = flag.String(`key`, ``, "Check DMN with key `<key>`")
+	fDmnVer = flag.Int(`ver`, 0, "Check DMN version `<ver>` (requires -key)")
+	fOutFile = flag.String(`file`, ``, "Store results in file `<file>`")
+	fOverlaps = flag.Bool(`overlaps`, false, "Show overlaps permitted by the hit policy")
+)
+
+func init() {
+	log.SetFlags(0)
+	flag.Usage = func() {
+		fmt.Fprintf(flag.CommandLine.Output(),
+			"Usage: %s [-url <url> [-id <id> | -key <key> [-ver <ver>]]] [options] [<dmn file> ...]\n",
+			os.Args[0])
+		flag.PrintDefaults()
+	}
+	flag.Parse()
+}
+
+func main() {
+
+	var err error
+	set := make(map[string]bool)
+
+	flag.Visit(func(f *flag.Flag) {
+		set[f.Name] = true
+	})
+
+	switch {
+	case !set[`url`] && flag.NArg() == 0:
+		err = fmt.Errorf(`-url or at least one DMN file is required`)
+	case !set[`url`] && (set[`id`] || set[`key`]):
+		err = fmt.Errorf(`-id and -key require -url`)
+	case !set[`key`] && set[`ver`]:
+		err = fmt.Errorf(`-ver requires -key`)
+	}
+
+	if err != nil {
+		log.Printf("%v\n\n", err)
+		flag.Usage()
+		os.Exit(2)
+	}
+
+	var out io.WriteCloser
+
+	if set[`file`] {
+		if out, err = os.Create(*fOutFile); err != nil {
+			log.Fatal(err)
+		}
+		defer out.Close()
+	} else {
+		out = os.Stdout
+	}
+
+	rows := [][]string{{`Severity`, `Source`, `Decision ID`, `Decision Name`, `Finding`, `Rules`, `Region`}}
+
+	if set[`url`] {
+
+		opts, err := apiflag.Options()
+
+		if err != nil {
+			log.Fatal(err)
+		}
+
+		api := api.NewDmnApi(*fSvcUrl, opts...)
+
+		switch {
+
+		case set[`id`], set[`key`]:
+
+			var dmn *model.Dmn
+
+			switch {
+			case set[`ver`]:
+				dmn, err = api.DmnByKeyVerTenant(*fDmnKey, *fDmnVer, apiflag.Tenant())
+			case set[`id`]:
+				dmn, err = api.DmnById(*fDmnId)
+			default:
+				dmn, err = api.DmnByKeyTenant(*fDmnKey, apiflag.Tenant())
+			}
+
+			if err != nil {
+				log.Fatal(err)
+			}
+
+			if set[`key`] {
+				rows = append(rows, check(*fSvcUrl, dmn, *fDmnKey)...)
+			} else {
+				rows = append(rows, check(*fSvcUrl, dmn)...)
+			}
+
+		default:
+
+			dmnList, err := api.DmnListWhere(apiflag.Query())
+
+			if err != nil {
+				log.Fatal(err)
+			}
+
+			dmnList.Sort()
+
+			for _, di := range *dmnList {
+
+				src := fmt.Sprintf(`%s key %s version %d`, *fSvcUrl, di.Key, di.Version)
+
+				if dmn, err := api.DmnById(di.Id); err != nil {
+					rows = append(rows, []string{`ERROR`, src, ``, ``, `Could not get DMN`, ``, err.Error()})
+				} else {
+					rows = append(rows, check(src, dmn, di.Key)...)
+				}
+			}
+		}
+	}
+
+	for _, file := range flag.Args() {
+
+		if fh, err := os.Open(file); err != nil {
+			rows = append(rows, []string{`ERROR`, file, ``, ``, `Could not read DMN`, ``, err.Error()})
+		} else if dmn, err := model.NewDmn(fh); err != nil {
+			fh.Close()
+			rows = append(rows, []string{`ERROR`, file, ``, ``, `Could not parse DMN`, ``, err.Error()})
+		} else {
+			fh.Close()
+			rows = append(rows, check(file, dmn)...)
+		}
+	}
+
+	if err := csv.NewWriter(out).WriteAll(rows); err != nil {
+		log.Fatal(err)
+	}
+}
+
+// check analyzes the decision tables of the decisions of a DMN with the
+// given ids, or of all its decisions if no ids are given, and returns a
+// report row for every violating overlap and gap found and for a
+// requirements graph with unknown references or cycles.
+func check(src string, dmn *model.Dmn, ids ...string) (rows [][]string) {
+
+	ds := dmn.DecisionTables()
+
+	if drg, err := dmn.Drg(); err != nil {
+		rows = append(rows, []string{`ERROR`, src, dmn.Id, dmn.Name, `Invalid requirements graph`, ``, err.Error()})
+	} else if err := drg.Validate(); err != nil {
+		rows = append(rows, []string{`ERROR`, src, dmn.Id, dmn.Name, `Invalid requirements graph`, ``, err.Error()})
+	}
+
+	if len(ids) > 0 {
+		ds = nil
+		for _, id := range ids {
+			if d, err := dmn.Decision(id); err != nil {
+				rows = append(rows, []string{`ERROR`, src, dmn.Id, dmn.Name, `Could not analyze DMN`, ``, err.Error()})
+			} else {
+				ds = append(ds, d)
+			}
+		}
+	} else if len(ds) == 0 {
+		return append(rows, []string{`ERROR`, src, dmn.Id, dmn.Name, `Could not analyze DMN`, ``, `no decision table`})
+	}
+
+	for _, d := range ds {
+		rows = append(rows, checkDecision(src, d)...)
+	}
+
+	return rows
+}
+
+// checkDecision analyzes the decision table of a decision.
+func checkDecision(src string, d *model.Decision) (rows [][]string) {
+
+	dt, err := d.Table()
+
+	if err != nil {
+		return [][]string{{`ERROR`, src, d.Id, d.Name, `Could not analyze DMN`, ``, err.Error()}}
+	}
+
+	rpt, err := analysis.Analyze(dt)
+
+	if err != nil {
+		return [][]string{{`ERROR`, src, d.Id, d.Name, `Could not analyze DMN`, ``, err.Error()}}
+	}
+
+	violations := make(map[*analysis.Overlap]bool)
+
+	for _, ov := range rpt.Violations() {
+		violations[ov] = true
+	}
+
+	for _, ov := range rpt.Overlaps {
+
+		rules := fmt.Sprintf(`%s, %s`, ov.RuleA, ov.RuleB)
+
+		if violations[ov] {
+			rows = append(rows, []string{`ERROR`, src, d.Id, d.Name,
+				fmt.Sprintf(`Overlap violates hit policy %s`, rpt.HitPolicy), rules, ov.Region.String()})
+		} else if *fOverlaps {
+			rows = append(rows, []string{`INFO`, src, d.Id, d.Name,
+				`Overlap`, rules, ov.Region.String()})
+		}
+	}
+
+	for _, gap := range rpt.Gaps {
+		rows = append(rows, []string{`WARNING`, src, d.Id, d.Name, `Gap`, ``, gap.String()})
+	}
+
+	return rows
+}
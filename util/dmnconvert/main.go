@@ -0,0 +1,142 @@
+// Copyright 2017 John Scherff
+//
+// Licensed under the Apache License, Version 2.0 (the "License");
+// you may not use this file except in compliance with the License.
+// You may obtain a copy of the License at
+//
+//     http://www.apache.org/licenses/LICENSE-2.0
+//
+// Unless required by applicable law or agreed to in writing, software
+// distributed under the License is distributed on an "AS IS" BASIS,
+// WITHOUT WARRANTIES OR CONDITIONS OF ANY KIND, either express or implied.
+// See the License for the specific language governing permissions and
+// limitations under the License.
+
+package main
+
+import (
+	`bytes`
+	`flag`
+	`fmt`
+	`io/ioutil`
+	`log`
+	`os`
+	`github.com/jscherff/dmnsdk/api`
+	`github.com/jscherff/dmnsdk/util/internal/apiflag`
+	`github.com/jscherff/dmnsdk/model`
+)
+
+var (
+	fSvcUrl = flag.String(`url`, ``, "Use service at `http[s]://<hostname>[:<port>]`")
+	fDmnId = flag.String(`id`, ``, "Convert DMN with ID `<id>`")
+	fDmnKey = flag.String(`key`, ``, "Convert DMN with key `<key>`")
+	fDmnVer = flag.Int(`ver`, 0, "Convert DMN version `<ver>` (requires -key)")
+	fOutFile = flag.String(`file`, ``, "Store results in file `<file>`")
+	fDetect = flag.Bool(`detect`, false, "Report the DMN version instead of converting")
+)
+
+func init() {
+	log.SetFlags(0)
+	flag.Usage = func() {
+		fmt.Fprintf(flag.CommandLine.Output(),
+			"Usage: %s [-url <url> -id <id> | -key <key> [-ver <ver>]] [options] [<dmn file>]\n",
+			os.Args[0])
+		flag.PrintDefaults()
+	}
+	flag.Parse()
+}
+
+func main() {
+
+	var err error
+	set := make(map[string]bool)
+
+	flag.Visit(func(f *flag.Flag) {
+		set[f.Name] = true
+	})
+
+	switch {
+	case set[`url`] && flag.NArg() > 0:
+		err = fmt.Errorf(`-url cannot be combined with a DMN file`)
+	case !set[`url`] && flag.NArg() != 1:
+		err = fmt.Errorf(`-url or a single DMN file is required`)
+	case set[`url`] && !set[`key`] && !set[`id`]:
+		err = fmt.Errorf(`-id or -key must be set`)
+	case !set[`key`] && set[`ver`]:
+		err = fmt.Errorf(`-ver requires -key`)
+	}
+
+	if err != nil {
+		log.Printf("%v\n\n", err)
+		flag.Usage()
+		os.Exit(2)
+	}
+
+	var b []byte
+
+	if set[`url`] {
+		b, err = fetch(set)
+	} else {
+		b, err = ioutil.ReadFile(flag.Arg(0))
+	}
+
+	if err != nil {
+		log.Fatal(err)
+	}
+
+	var out []byte
+
+	if *fDetect {
+		if dmn, err := model.NewDmn(bytes.NewReader(b)); err != nil {
+			log.Fatal(err)
+		} else if version, err := dmn.Version(); err != nil {
+			log.Fatal(err)
+		} else {
+			out = []byte(version + "\n")
+		}
+	} else if out, err = model.UpgradeDmn(bytes.NewReader(b)); err != nil {
+		log.Fatal(err)
+	}
+
+	if set[`file`] {
+		err = ioutil.WriteFile(*fOutFile, out, 0644)
+	} else {
+		_, err = os.Stdout.Write(out)
+	}
+
+	if err != nil {
+		log.Fatal(err)
+	}
+}
+
+// fetch retrieves the DMN XML of the selected definition from the service.
+func fetch(set map[string]bool) ([]byte, error) {
+
+	var (
+		dx *model.DmnXml
+		err error
+	)
+
+	opts, err := apiflag.Options()
+
+	if err != nil {
+		return nil, err
+	}
+
+	dmnApi := api.NewDmnApi(*fSvcUrl, opts...)
+
+	switch {
+	case set[`ver`]:
+		dx, err = dmnApi.DmnXmlByKeyVerTenant(*fDmnKey, *fDmnVer, apiflag.Tenant())
+	case set[`id`]:
+		dx, err = dmnApi.DmnXmlById(*fDmnId)
+	case set[`key`]:
+		dx, err = dmnApi.DmnXmlByKeyTenant(*fDmnKey, apiflag.Tenant())
+	}
+
+	if err != nil {
+		return nil, err
+	}
+
+	return []byte(dx.DmnXml), nil
+}
@@ -17,12 +17,14 @@ package main
 import `flag`
 
 var (
-	fSvcUrl1 = flag.String(`url1`, ``, "Use service at `http[s]://<hostname>[:<port>]`")
-	fSvcUrl2 = flag.String(`url2`, ``, "Use service at `http[s]://<hostname>[:<port>]`")
+	fSvcUrl1 = flag.String(`url1`, ``, "Use service at `http[s]://<hostname>[:<port>]` or dmnsave snapshot directory")
+	fSvcUrl2 = flag.String(`url2`, ``, "Use service at `http[s]://<hostname>[:<port>]` or dmnsave snapshot directory")
 	fOutFile = flag.String(`file`, ``, "Store results in file `<file>`")
 	fWarning = flag.Bool(`warning`, true, "Show warning message when DMNs do not match")
 	fSuccess = flag.Bool(`success`, false, "Show success message when DMNs match")
 	fFailure = flag.Bool(`failure`, false, "Show failure message when DMNs missing or cannot be processed")
 	fDetails = flag.Bool(`details`, false, "Show detailed differences between DMN elements")
 	fVerbose = flag.Bool(`verbose`, false, "Show matching DMN elements along with differences")
+	fSemantic = flag.Bool(`semantic`, false, "Compare decision logic by content, ignoring element ids")
+	fWorkers = flag.Int(`workers`, 1, "Fetch up to `<n>` DMNs concurrently")
 )
@@ -15,6 +15,7 @@
 package main
 
 import (
+	`context`
 	`encoding/csv`
 	`flag`
 	`log`
@@ -23,6 +24,7 @@ import (
 	`reflect`
 	`strconv`
 	`github.com/jscherff/dmnsdk/api`
+	`github.com/jscherff/dmnsdk/util/internal/apiflag`
 	`github.com/jscherff/dmnsdk/model`
 )
 
@@ -31,6 +33,9 @@ const (
 	cmpSuccess = `DMNs are Identical`
 	cmpWarning = `DMNs are Different`
 	dmnFailure = `Could not get DMN`
+	dmnMissing = `DMN is Missing`
+	dmnInvalid = `Could not parse DMN`
+	decMissing = `Decision is Missing`
 	elmWarning = `Elements are Different`
 	elmSuccess = `Elements are Identical`
 	elmFailure = `Could not process DMN`
@@ -132,12 +137,21 @@ func main() {
 
 	// Get the API for both environments.
 
-	api1 := api.NewDmnApi(*fSvcUrl1)
-	api2 := api.NewDmnApi(*fSvcUrl2)
+	api1, err := newDmnApi(*fSvcUrl1)
+
+	if err != nil {
+		log.Fatal(err)
+	}
+
+	api2, err := newDmnApi(*fSvcUrl2)
+
+	if err != nil {
+		log.Fatal(err)
+	}
 
 	// Get the DmnList for the first environment and sort it.
 
-	dmnList, err := api1.DmnList()
+	dmnList, err := api1.DmnListWhere(apiflag.Query())
 
 	if err != nil {
 		log.Fatal(err)
@@ -145,19 +159,49 @@ func main() {
 
 	dmnList.Sort()
 
-	// Iterate through keys and versions of first environment.
+	// Fetch keys and versions of first environment from both environments
+	// and compare them in list order. A definition is one decision of its
+	// DMN, so only that decision is compared; otherwise a change to one
+	// decision of a DRD would be reported under every decision in it.
 
-	for _, di := range *dmnList {
+	fetch := func(ctx context.Context, di *model.DmnInfo) (interface{}, error) {
+		var p pair
+		if p.dmn1, p.err1 = api1.DmnByKeyVerTenantContext(ctx, di.Key, di.Version, di.TenantId); p.err1 == nil {
+			p.dmn2, p.err2 = api2.DmnByKeyVerTenantContext(ctx, di.Key, di.Version, di.TenantId)
+		}
+		return &p, nil
+	}
 
-		if dmn1, err := api1.DmnByKeyVer(di.Key, di.Version); err != nil {
+	for res := range api.Fetch(context.Background(), *dmnList, *fWorkers, fetch) {
+
+		di, p := res.DmnInfo, res.Value.(*pair)
+
+		if p.err1 != nil {
+			if *fFailure {
+				failure(di, *fSvcUrl1, p.err1)
+			}
+			continue
+		} else if p.err2 != nil {
+			if *fFailure {
+				failure(di, *fSvcUrl2, p.err2)
+			}
+			continue
+		}
+
+		d1, err1 := p.dmn1.Decision(di.Key)
+		d2, err2 := p.dmn2.Decision(di.Key)
+
+		if err1 != nil {
 			if *fFailure {
-				report.Failure(di, dmnFailure, *fSvcUrl1, err.Error())
+				report.Failure(di, decMissing, *fSvcUrl1, err1.Error())
 			}
-		} else if dmn2, err := api2.DmnByKeyVer(di.Key, di.Version); err != nil {
+		} else if err2 != nil {
 			if *fFailure {
-				report.Failure(di, dmnFailure, *fSvcUrl2, err.Error())
+				report.Failure(di, decMissing, *fSvcUrl2, err2.Error())
 			}
-		} else if reflect.DeepEqual(dmn1, dmn2) {
+		} else if *fSemantic {
+			semantic(di, d1, d2)
+		} else if reflect.DeepEqual(d1, d2) {
 			if *fSuccess {
 				report.Success(di, cmpSuccess)
 			}
@@ -166,7 +210,7 @@ func main() {
 				report.Warning(di, cmpWarning)
 			}
 			if *fDetails {
-				diff(di, dmn1, dmn2)
+				diff(di, d1, d2)
 			}
 		}
 	}
@@ -176,13 +220,46 @@ func main() {
 	}
 }
 
-func diff(di *model.DmnInfo, dmn1, dmn2 *model.Dmn) {
+// pair holds a DMN fetched from both environments.
+type pair struct {
+	dmn1, dmn2		*model.Dmn
+	err1, err2		error
+}
 
-	if de, err := model.NewDmnElements(dmn1); err != nil {
+// newDmnApi returns a DmnApi for a snapshot directory or a service URL.
+// Each service gets its own options, so that one failing service does not
+// open the circuit breaker of the other.
+func newDmnApi(src string) (api.DmnApi, error) {
+	if fi, err := os.Stat(src); err == nil && fi.IsDir() {
+		return api.NewDmnDirApi(src), nil
+	} else if opts, err := apiflag.Options(); err != nil {
+		return nil, err
+	} else {
+		return api.NewDmnApi(src, opts...), nil
+	}
+}
+
+// failure reports a DMN that could not be retrieved from a service,
+// distinguishing DMNs missing from the service from DMNs that could not
+// be parsed.
+func failure(di *model.DmnInfo, svc string, err error) {
+	switch {
+	case api.IsNotFound(err):
+		report.Failure(di, dmnMissing, svc, err.Error())
+	case api.IsDecodeError(err):
+		report.Failure(di, dmnInvalid, svc, err.Error())
+	default:
+		report.Failure(di, dmnFailure, svc, err.Error())
+	}
+}
+
+func diff(di *model.DmnInfo, d1, d2 *model.Decision) {
+
+	if de, err := model.NewDmnElements(d1); err != nil {
 		if *fFailure {
 			report.Failure(di, elmFailure, *fSvcUrl1, err.Error())
 		}
-	} else if err := de.Compare(dmn2); err != nil {
+	} else if err := de.Compare(d2); err != nil {
 		if *fFailure {
 			report.Failure(di, elmFailure, *fSvcUrl2, err.Error())
 		}
@@ -201,3 +278,33 @@ func diff(di *model.DmnInfo, dmn1, dmn2 *model.Dmn) {
 		}
 	}
 }
+
+func semantic(di *model.DmnInfo, d1, d2 *model.Decision) {
+
+	delta := d1.SemanticDelta(d2)
+
+	switch {
+
+	case len(delta) == 0:
+		if *fSuccess {
+			report.Success(di, cmpSuccess)
+		}
+
+	default:
+		if *fWarning {
+			report.Warning(di, cmpWarning)
+		}
+		if *fDetails {
+			for _, change := range delta {
+				switch change.Change {
+				case model.ChangeRemoved:
+					report.Warning(di, elmWarning, *fSvcUrl1, change.String())
+				case model.ChangeAdded:
+					report.Warning(di, elmWarning, *fSvcUrl2, change.String())
+				default:
+					report.Warning(di, elmWarning, `Both Services`, change.String())
+				}
+			}
+		}
+	}
+}
@@ -22,6 +22,7 @@ import (
 	`os`
 	`strconv`
 	`github.com/jscherff/dmnsdk/api`
+	`github.com/jscherff/dmnsdk/util/internal/apiflag`
 	`github.com/jscherff/dmnsdk/model`
 )
 
@@ -34,6 +35,9 @@ const (
 var (
 	fSvcUrl = flag.String(`url`, ``, "Use service at `http[s]://<hostname>[:<port>]`")
 	fCsvFile = flag.String(`file`, ``, "Store results in file `<file>`")
+	fKeyLike = flag.String(`keylike`, ``, "List only keys matching `<pattern>` (% and _ wildcards)")
+	fNameLike = flag.String(`namelike`, ``, "List only names matching `<pattern>` (% and _ wildcards)")
+	fLatest = flag.Bool(`latest`, false, `List only the latest version of each key`)
 )
 
 func init() {
@@ -64,9 +68,25 @@ func main() {
 		out io.WriteCloser
 	)
 
-	api := api.NewDmnApi(*fSvcUrl)
+	opts, err := apiflag.Options()
 
-	if dmns, err = api.DmnList(); err != nil {
+	if err != nil {
+		log.Fatal(err)
+	}
+
+	dmnApi := api.NewDmnApi(*fSvcUrl, opts...)
+
+	query := &api.DmnQuery{
+		KeyLike: *fKeyLike,
+		NameLike: *fNameLike,
+		LatestVersion: *fLatest,
+	}
+
+	if tenant := apiflag.Tenant(); tenant != `` {
+		query.TenantIdIn = []string{tenant}
+	}
+
+	if dmns, err = dmnApi.DmnListWhere(query); err != nil {
 		log.Fatal(err)
 	}
 
@@ -80,10 +100,10 @@ func main() {
 	}
 
 	dmns.Sort()
-	rows = append(rows, []string{`name`, `key`, `version`, `id`})
+	rows = append(rows, []string{`name`, `key`, `version`, `id`, `tenantId`})
 
 	for _, dmn := range *dmns {
-		row := []string{dmn.Name, dmn.Key, strconv.Itoa(dmn.Version), dmn.Id}
+		row := []string{dmn.Name, dmn.Key, strconv.Itoa(dmn.Version), dmn.Id, dmn.TenantId}
 		rows = append(rows, row)
 	}
 
@@ -0,0 +1,196 @@
+// Copyright 2017 John Scherff
+//
+// Licensed under the Apache License, Version 2.0 (the "License");
+// you may not use this file except in compliance with the License.
+// You may obtain a copy of the License at
+//
+//     http://www.apache.org/licenses/LICENSE-2.0
+//
+// Unless required by applicable law or agreed to in writing, software
+// distributed under the License is distributed on an "AS IS" BASIS,
+// WITHOUT WARRANTIES OR CONDITIONS OF ANY KIND, either express or implied.
+// See the License for the specific language governing permissions and
+// limitations under the License.
+
+
+package main
+
+import (
+	`flag`
+	`fmt`
+	`log`
+	`io`
+	`os`
+	`github.com/jscherff/dmnsdk/api`
+	`github.com/jscherff/dmnsdk/util/internal/apiflag`
+	`github.com/jscherff/dmnsdk/model`
+)
+
+var (
+	fSvcUrl = flag.String(`url`, ``, "Use service at `http[s]://<hostname>[:<port>]`")
+	fDmnId = flag.String(`id`, ``, "Retrieve DMN with ID `<id>`")
+	fDmnKey = flag.String(`key`, ``, "Retrieve DMN with key `<key>`")
+	fDmnVer = flag.Int(`ver`, 0, "Retrieve DMN version `<ver>` (requires -key)")
+	fFormat = flag.String(`format`, `html`, "Render the decision table as `<html|svg>`")
+	fDiagram = flag.Bool(`diagram`, false, "Store the deployed diagram image instead of rendering")
+	fOutFile = flag.String(`file`, ``, "Store results in file `<file>`")
+	fDecision = flag.String(`decision`, ``, "Render the decision with ID `<id>` (default the -key decision)")
+)
+
+func init() {
+	log.SetFlags(0)
+	flag.Parse()
+}
+
+func main() {
+
+	var err error
+	set := make(map[string]bool)
+
+	flag.Visit(func(f *flag.Flag) {
+		set[f.Name] = true
+	})
+
+	switch {
+	case !set[`url`]:
+		err = fmt.Errorf(`-url flag is required`)
+	case !set[`key`] && !set[`id`]:
+		err = fmt.Errorf(`-id or -key must be set`)
+	case !set[`key`] && set[`ver`]:
+		err = fmt.Errorf(`-ver requires -key`)
+	case *fFormat != `html` && *fFormat != `svg`:
+		err = fmt.Errorf(`-format must be html or svg`)
+	case *fDiagram && (set[`format`] || set[`decision`]):
+		err = fmt.Errorf(`-diagram cannot be combined with -format or -decision`)
+	}
+
+	if err != nil {
+		log.Printf("%v\n\n", err)
+		flag.Usage()
+		os.Exit(2)
+	}
+
+	opts, err := apiflag.Options()
+
+	if err != nil {
+		log.Fatal(err)
+	}
+
+	dmnApi := api.NewDmnApi(*fSvcUrl, opts...)
+
+	var out io.WriteCloser
+
+	if set[`file`] {
+		if out, err = os.Create(*fOutFile); err != nil {
+			log.Fatal(err)
+		}
+		defer out.Close()
+	} else {
+		out = os.Stdout
+	}
+
+	if *fDiagram {
+		err = writeDiagram(dmnApi, out, set)
+	} else {
+		err = writeTable(dmnApi, out, set)
+	}
+
+	if err != nil {
+		log.Fatal(err)
+	}
+}
+
+// writeTable renders the decision table of the selected DMN.
+func writeTable(dmnApi api.DmnApi, out io.Writer, set map[string]bool) (error) {
+
+	var (
+		dmn *model.Dmn
+		err error
+	)
+
+	switch {
+	case set[`ver`]:
+		dmn, err = dmnApi.DmnByKeyVerTenant(*fDmnKey, *fDmnVer, apiflag.Tenant())
+	case set[`id`]:
+		dmn, err = dmnApi.DmnById(*fDmnId)
+	case set[`key`]:
+		dmn, err = dmnApi.DmnByKeyTenant(*fDmnKey, apiflag.Tenant())
+	}
+
+	if err != nil {
+		return err
+	}
+
+	d, err := decision(dmn, set)
+
+	if err != nil {
+		return err
+	}
+
+	dt, err := d.Table()
+
+	if err != nil {
+		return err
+	}
+
+	title := d.Name
+
+	if title == `` {
+		title = d.Id
+	}
+
+	if *fFormat == `svg` {
+		return dt.Svg(out, title)
+	}
+
+	return dt.Html(out, title)
+}
+
+// decision selects the decision to render: the one named by -decision,
+// else the one named by -key, else the only decision table of the DMN.
+func decision(dmn *model.Dmn, set map[string]bool) (*model.Decision, error) {
+
+	switch {
+	case set[`decision`]:
+		return dmn.Decision(*fDecision)
+	case set[`key`]:
+		return dmn.Decision(*fDmnKey)
+	}
+
+	if ds := dmn.DecisionTables(); len(ds) == 1 {
+		return ds[0], nil
+	} else if len(ds) == 0 {
+		return nil, fmt.Errorf(`DMN has no decision table`)
+	} else {
+		return nil, fmt.Errorf(`DMN has %d decision tables; select one with -decision`, len(ds))
+	}
+}
+
+// writeDiagram writes the diagram image deployed with the selected DMN.
+func writeDiagram(dmnApi api.DmnApi, out io.Writer, set map[string]bool) (error) {
+
+	var (
+		diagram *model.DmnDiagram
+		err error
+	)
+
+	switch {
+	case set[`ver`]:
+		if di, e := dmnApi.DmnInfoByKeyVerTenant(*fDmnKey, *fDmnVer, apiflag.Tenant()); e != nil {
+			err = e
+		} else {
+			diagram, err = dmnApi.DmnDiagramById(di.Id)
+		}
+	case set[`id`]:
+		diagram, err = dmnApi.DmnDiagramById(*fDmnId)
+	case set[`key`]:
+		diagram, err = dmnApi.DmnDiagramByKeyTenant(*fDmnKey, apiflag.Tenant())
+	}
+
+	if err != nil {
+		return err
+	}
+
+	_, err = out.Write(diagram.Bytes())
+	return err
+}
@@ -15,11 +15,22 @@
 package main
 
 import (
+	`context`
+	`errors`
 	`flag`
 	`fmt`
 	`log`
 	`os`
+	`path/filepath`
 	`github.com/jscherff/dmnsdk/api`
+	`github.com/jscherff/dmnsdk/model`
+	`github.com/jscherff/dmnsdk/util/internal/apiflag`
+)
+
+var (
+	fSvcUrl = flag.String(`url`, `http://esbeap.24hourfit.com:8180`, "Use service at `http[s]://<hostname>[:<port>]`")
+	fOutDir = flag.String(`dir`, `.`, "Store snapshot files in directory `<dir>`")
+	fWorkers = flag.Int(`workers`, 1, "Fetch up to `<n>` DMNs concurrently")
 )
 
 func init() {
@@ -29,9 +40,15 @@ func init() {
 
 func main() {
 
-	api := api.NewDmnApi(`http://esbeap.24hourfit.com:8180`)
+	opts, err := apiflag.Options()
+
+	if err != nil {
+		log.Fatal(err)
+	}
+
+	dmnApi := api.NewDmnApi(*fSvcUrl, opts...)
 
-	dmnList, err := api.DmnList()
+	dmnList, err := dmnApi.DmnListWhere(apiflag.Query())
 
 	if err != nil {
 		log.Fatal(err)
@@ -39,32 +56,83 @@ func main() {
 
 	dmnList.Sort()
 
-	for _, di := range *dmnList {
+	if b, err := dmnList.Json(); err != nil {
+		log.Println(err)
+	} else {
+		save(api.FileDmnList, b)
+	}
+
+	// Fetch the DMN XML and diagrams concurrently and save the files in
+	// list order. A diagram that cannot be fetched does not prevent the
+	// DMN from being saved.
+
+	fetch := func(ctx context.Context, di *model.DmnInfo) (interface{}, error) {
+
+		xml, err := dmnApi.DmnXmlByKeyVerTenantContext(ctx, di.Key, di.Version, di.TenantId)
+
+		if err != nil {
+			return nil, err
+		}
 
-		id, key, ver := di.Id, di.Key, di.Version
+		diagram, err := dmnApi.DmnDiagramByIdContext(ctx, di.Id)
 
-		if xml, err := api.DmnXmlByKeyVer(key, ver); err != nil {
+		if err != nil && !errors.Is(err, api.ErrNoDiagram) {
 			log.Println(err)
-		} else if b, err := xml.Xml(); err != nil {
+		}
+
+		return &resources{xml, diagram}, nil
+	}
+
+	for res := range api.Fetch(context.Background(), *dmnList, *fWorkers, fetch) {
+
+		id, key, ver := res.DmnInfo.Id, res.DmnInfo.Key, res.DmnInfo.Version
+
+		// Keys are not unique across tenants, so DMNs of a tenant are
+		// only saved by id.
+
+		byKey := res.DmnInfo.TenantId == ``
+
+		if res.Err != nil {
+			log.Println(res.Err)
+			continue
+		}
+
+		xml, diagram := res.Value.(*resources).xml, res.Value.(*resources).diagram
+
+		if b, err := xml.Xml(); err != nil {
 			log.Println(err)
 		} else {
-			save(fmt.Sprintf(`dmnxml_key_%s_ver_%d.xml`, key, ver), b)
-			save(fmt.Sprintf(`dmnxml_id_%s.xml`, id), b)
+			if byKey {
+				save(fmt.Sprintf(api.FileXmlByKeyVer, key, ver), b)
+			}
+			save(fmt.Sprintf(api.FileXmlById, id), b)
 		}
 
-		if dmn, err := api.DmnByKeyVer(key, ver); err != nil {
+		if dmn, err := model.NewDmn(xml.DmnXml); err != nil {
 			log.Println(err)
 		} else if b, err := dmn.Json(); err != nil {
 			log.Println(err)
 		} else {
-			save(fmt.Sprintf(`dmn_key_%s_ver_%d.json`, key, ver), b)
-			save(fmt.Sprintf(`dmn_id_%s.json`, id), b)
+			if byKey {
+				save(fmt.Sprintf(api.FileDmnByKeyVer, key, ver), b)
+			}
+			save(fmt.Sprintf(api.FileDmnById, id), b)
+		}
+
+		if diagram != nil {
+			save(fmt.Sprintf(api.FileDiagramById, id, diagram.Ext()), diagram.Bytes())
 		}
 	}
 }
 
+// resources holds the resources fetched for a decision definition.
+type resources struct {
+	xml *model.DmnXml
+	diagram *model.DmnDiagram
+}
+
 func save(f string, b []byte) {
-	if fh, err := os.Create(f); err != nil {
+	if fh, err := os.Create(filepath.Join(*fOutDir, f)); err != nil {
 		log.Println(err)
 	} else {
 		defer fh.Close()
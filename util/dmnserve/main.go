@@ -0,0 +1,59 @@
+// Copyright 2018 John Scherff
+//
+// Licensed under the Apache License, Version 2.0 (the "License");
+// you may not use this file except in compliance with the License.
+// You may obtain a copy of the License at
+//
+//     http://www.apache.org/licenses/LICENSE-2.0
+//
+// Unless required by applicable law or agreed to in writing, software
+// distributed under the License is distributed on an "AS IS" BASIS,
+// WITHOUT WARRANTIES OR CONDITIONS OF ANY KIND, either express or implied.
+// See the License for the specific language governing permissions and
+// limitations under the License.
+
+package main
+
+import (
+	`flag`
+	`log`
+	`net/http`
+	`os`
+	`github.com/jscherff/dmnsdk/server`
+)
+
+var (
+	fDmnDir = flag.String(`dir`, ``, "Serve DMN files in directory `<dir>`")
+	fListen = flag.String(`listen`, `:8180`, "Listen on address `[<hostname>]:<port>`")
+)
+
+func init() {
+	log.SetFlags(log.Flags() | log.Lshortfile)
+	flag.Parse()
+}
+
+func main() {
+
+	set := make(map[string]bool)
+
+	flag.Visit(func(f *flag.Flag) {
+		set[f.Name] = true
+	})
+
+	if !set[`dir`] {
+		log.Println(`-dir flag is required`)
+		flag.Usage()
+		os.Exit(2)
+	}
+
+	repo, err := server.NewRepository(*fDmnDir)
+
+	if err != nil {
+		log.Fatal(err)
+	}
+
+	log.Printf(`serving %d decision definitions from %s at %s%s`,
+		len(repo.DmnList()), *fDmnDir, *fListen, server.Prefix)
+
+	log.Fatal(http.ListenAndServe(*fListen, server.NewHandler(repo)))
+}
@@ -0,0 +1,274 @@
+// Copyright 2017 John Scherff
+//
+// Licensed under the Apache License, Version 2.0 (the "License");
+// you may not use this file except in compliance with the License.
+// You may obtain a copy of the License at
+//
+//     http://www.apache.org/licenses/LICENSE-2.0
+//
+// Unless required by applicable law or agreed to in writing, software
+// distributed under the License is distributed on an "AS IS" BASIS,
+// WITHOUT WARRANTIES OR CONDITIONS OF ANY KIND, either express or implied.
+// See the License for the specific language governing permissions and
+// limitations under the License.
+
+
+package main
+
+import (
+	`bufio`
+	`encoding/csv`
+	`flag`
+	`fmt`
+	`log`
+	`io`
+	`os`
+	`strconv`
+	`strings`
+	`github.com/jscherff/dmnsdk/api`
+	`github.com/jscherff/dmnsdk/util/internal/apiflag`
+	`github.com/jscherff/dmnsdk/model`
+)
+
+var (
+	fSvcUrl = flag.String(`url`, ``, "Use service at `http[s]://<hostname>[:<port>]`")
+	fCsvFile = flag.String(`file`, ``, "Store results in file `<file>`")
+	fKeyLike = flag.String(`keylike`, ``, "Check only keys matching `<pattern>` (% and _ wildcards)")
+	fLatest = flag.Bool(`latest`, false, `Check only the latest version of each key`)
+	fMin = flag.Int(`min`, 0, "Require a history time to live of at least `<days>`")
+	fMax = flag.Int(`max`, 0, "Require a history time to live of at most `<days>`")
+	fDefault = flag.Int(`default`, 0, "Set a missing history time to live to `<days>`")
+	fApply = flag.Bool(`apply`, false, `Apply the fixes (default is a dry run)`)
+	fConfirm = flag.Bool(`confirm`, false, `Ask for confirmation before each fix (requires -apply)`)
+	fDrift = flag.Bool(`drift`, false, `Also report values that differ from the DMN XML`)
+)
+
+// Problems reported for a history time to live.
+const (
+	problemMissing = `missing`
+	problemBelowMin = `below minimum`
+	problemAboveMax = `above maximum`
+	problemDrift = `differs from XML`
+	problemUnchecked = `could not check XML`
+)
+
+// Fix statuses.
+const (
+	statusDryRun = `dry run`
+	statusNoFix = `no fix (requires -default)`
+	statusRedeploy = `no fix (redeploy the DMN)`
+	statusUnchecked = `no fix (check the DMN XML)`
+	statusUpdated = `updated`
+	statusDeclined = `declined`
+)
+
+func init() {
+	log.SetFlags(0)
+	flag.Parse()
+}
+
+func main() {
+
+	var err error
+	set := make(map[string]bool)
+
+	flag.Visit(func(f *flag.Flag) {
+		set[f.Name] = true
+	})
+
+	switch {
+	case !set[`url`]:
+		err = fmt.Errorf(`-url flag is required`)
+	case *fMin < 0 || *fMax < 0 || *fDefault < 0:
+		err = fmt.Errorf(`-min, -max and -default cannot be negative`)
+	case set[`max`] && *fMax < *fMin:
+		err = fmt.Errorf(`-max cannot be less than -min`)
+	case set[`default`] && !inRange(*fDefault, set):
+		err = fmt.Errorf(`-default must be within -min and -max`)
+	case *fConfirm && !*fApply:
+		err = fmt.Errorf(`-confirm requires -apply`)
+	}
+
+	if err != nil {
+		log.Printf("%v\n\n", err)
+		flag.Usage()
+		os.Exit(2)
+	}
+
+	opts, err := apiflag.Options()
+
+	if err != nil {
+		log.Fatal(err)
+	}
+
+	dmnApi := api.NewDmnApi(*fSvcUrl, opts...)
+
+	query := &api.DmnQuery{
+		KeyLike: *fKeyLike,
+		LatestVersion: *fLatest,
+	}
+
+	if tenant := apiflag.Tenant(); tenant != `` {
+		query.TenantIdIn = []string{tenant}
+	}
+
+	dmns, err := dmnApi.DmnListWhere(query)
+
+	if err != nil {
+		log.Fatal(err)
+	}
+
+	var out io.WriteCloser
+
+	if set[`file`] {
+		if out, err = os.Create(*fCsvFile); err != nil {
+			log.Fatal(err)
+		}
+		defer out.Close()
+	} else {
+		out = os.Stdout
+	}
+
+	dmns.Sort()
+
+	rows := [][]string{{`key`, `version`, `id`, `tenantId`, `historyTimeToLive`, `problem`, `fix`, `status`}}
+	stdin := bufio.NewReader(os.Stdin)
+	unresolved := 0
+
+	for _, di := range *dmns {
+
+		problem, fix := check(di.HistoryTtl, set)
+
+		if *fDrift {
+			if p := drift(dmnApi, di); p == `` {
+				// No drift.
+			} else if problem == `` {
+				problem = p
+			} else {
+				problem += `; ` + p
+			}
+		}
+
+		if problem == `` {
+			continue
+		}
+
+		var status string
+
+		switch {
+		case fix == nil && strings.HasPrefix(problem, problemDrift):
+			status = statusRedeploy
+		case fix == nil && strings.HasPrefix(problem, problemUnchecked):
+			status = statusUnchecked
+		case fix == nil:
+			status = statusNoFix
+		case !*fApply:
+			status = statusDryRun
+		case *fConfirm && !confirm(stdin, di, *fix):
+			status = statusDeclined
+		default:
+			if err := dmnApi.UpdateHistoryTtlById(di.Id, fix); err != nil {
+				status = `error: ` + err.Error()
+			} else {
+				status = statusUpdated
+			}
+		}
+
+		if status != statusUpdated {
+			unresolved++
+		}
+
+		rows = append(rows, []string{
+			di.Key, strconv.Itoa(di.Version), di.Id, di.TenantId,
+			days(di.HistoryTtl), problem, days(fix), status,
+		})
+	}
+
+	if err := csv.NewWriter(out).WriteAll(rows); err != nil {
+		log.Fatal(err)
+	}
+
+	// Exit with a non-zero status while definitions remain out of policy,
+	// so that the audit can gate a pipeline.
+
+	if unresolved > 0 {
+		os.Exit(1)
+	}
+}
+
+// check returns the problem with a history time to live, if any, and the
+// value that fixes it, if one is known. Values outside the policy range
+// are moved to the nearest bound; missing values are set to -default.
+func check(ttl *int, set map[string]bool) (string, *int) {
+
+	switch {
+	case ttl == nil && set[`default`]:
+		return problemMissing, fDefault
+	case ttl == nil:
+		return problemMissing, nil
+	case *ttl < *fMin:
+		return problemBelowMin, fMin
+	case set[`max`] && *ttl > *fMax:
+		return problemAboveMax, fMax
+	}
+
+	return ``, nil
+}
+
+// drift returns a problem if the history time to live reported by the
+// engine differs from the one in the DMN XML of the definition, or if the
+// DMN XML could not be checked.
+func drift(dmnApi api.DmnApi, di *model.DmnInfo) (string) {
+
+	dmn, err := dmnApi.DmnById(di.Id)
+
+	if err != nil {
+		return fmt.Sprintf(`%s: %v`, problemUnchecked, err)
+	}
+
+	switch err := di.CheckHistoryTtl(dmn).(type) {
+	case nil:
+		return ``
+	case *model.HistoryTtlError:
+		return fmt.Sprintf(`%s (%s)`, problemDrift, err.XmlString())
+	default:
+		return fmt.Sprintf(`%s: %v`, problemUnchecked, err)
+	}
+}
+
+// inRange reports whether a number of days satisfies the policy range.
+func inRange(n int, set map[string]bool) (bool) {
+	return n >= *fMin && (!set[`max`] || n <= *fMax)
+}
+
+// confirm asks whether to apply a fix. Anything but y or yes declines.
+func confirm(in *bufio.Reader, di *model.DmnInfo, fix int) (bool) {
+
+	current := `none`
+
+	if di.HistoryTtl != nil {
+		current = fmt.Sprintf(`%d days`, *di.HistoryTtl)
+	}
+
+	fmt.Fprintf(os.Stderr, "Set history time to live of %s from %s to %d days? [y/N] ",
+		di.Id, current, fix)
+
+	answer, _ := in.ReadString('\n')
+
+	switch strings.ToLower(strings.TrimSpace(answer)) {
+	case `y`, `yes`:
+		return true
+	}
+
+	return false
+}
+
+// days formats a history time to live, which may be missing.
+func days(ttl *int) (string) {
+
+	if ttl == nil {
+		return ``
+	}
+
+	return strconv.Itoa(*ttl)
+}
@@ -0,0 +1,138 @@
+// Copyright 2018 John Scherff
+//
+// Licensed under the Apache License, Version 2.0 (the "License");
+// you may not use this file except in compliance with the License.
+// You may obtain a copy of the License at
+//
+//     http://www.apache.org/licenses/LICENSE-2.0
+//
+// Unless required by applicable law or agreed to in writing, software
+// distributed under the License is distributed on an "AS IS" BASIS,
+// WITHOUT WARRANTIES OR CONDITIONS OF ANY KIND, either express or implied.
+// See the License for the specific language governing permissions and
+// limitations under the License.
+
+// Package apiflag defines the command-line flags that configure the REST
+// API client, shared by the utilities that use it. The client key flag is
+// -certkey rather than -key, which the utilities use for the DMN key.
+package apiflag
+
+import (
+	`flag`
+	`fmt`
+	`net/url`
+	`os`
+	`strings`
+	`time`
+	`github.com/jscherff/dmnsdk/api`
+)
+
+// PasswordEnv is the environment variable holding the basic authentication
+// password when -user does not include one.
+const PasswordEnv = `DMN_PASSWORD`
+
+var (
+	fUser = flag.String(`user`, ``, "Authenticate as `<user>[:<password>]` (password defaults to $" + PasswordEnv + ")")
+	fTokenFile = flag.String(`token-file`, ``, "Send the bearer token in `<file>`, reread when it changes")
+	fTokenEnv = flag.String(`token-env`, ``, "Send the bearer token in environment variable `<name>`")
+	fPageSize = flag.Int(`pagesize`, 0, "Fetch definition lists in pages of `<n>` definitions")
+	fTimeout = flag.Duration(`timeout`, 0, "Abandon requests that take longer than `<duration>` (e.g. 30s)")
+	fCaCert = flag.String(`cacert`, ``, "Trust the CA certificates in PEM `<file>`")
+	fCert = flag.String(`cert`, ``, "Present the client certificate in PEM `<file>` (requires -certkey)")
+	fCertKey = flag.String(`certkey`, ``, "Use the client certificate key in PEM `<file>` (requires -cert)")
+	fProxy = flag.String(`proxy`, ``, "Send requests through proxy `<url>`")
+	fRetries = flag.Int(`retries`, 0, "Retry failed idempotent requests up to `<n>` times with backoff")
+	fBreaker = flag.Int(`breaker`, 0, "Stop contacting the server after `<n>` consecutive failures")
+	fTenant = flag.String(`tenant`, ``, "Only use DMNs of tenant `<id>`")
+)
+
+// breakerCooldown is how long the circuit breaker stays open before it
+// lets a trial request through.
+const breakerCooldown = 30 * time.Second
+
+// Tenant returns the tenant id selected by the -tenant flag, if any.
+func Tenant() (string) {
+	return *fTenant
+}
+
+// Query returns a query for the DMNs selected by the -tenant flag, or nil
+// to select all DMNs.
+func Query() (*api.DmnQuery) {
+
+	if *fTenant == `` {
+		return nil
+	}
+
+	return &api.DmnQuery{TenantIdIn: []string{*fTenant}}
+}
+
+// Options returns the client options selected by the flags. It must be
+// called after flag.Parse, once per client: each call creates a new
+// circuit breaker.
+func Options() ([]api.Option, error) {
+
+	var (
+		opts []api.Option
+		creds []api.Credentials
+	)
+
+	if *fUser != `` {
+		if i := strings.Index(*fUser, `:`); i >= 0 {
+			creds = append(creds, api.BasicAuth((*fUser)[:i], (*fUser)[i+1:]))
+		} else {
+			creds = append(creds, api.BasicAuth(*fUser, os.Getenv(PasswordEnv)))
+		}
+	}
+
+	if *fTokenFile != `` {
+		creds = append(creds, api.TokenFile(*fTokenFile))
+	}
+
+	if *fTokenEnv != `` {
+		creds = append(creds, api.TokenEnv(*fTokenEnv))
+	}
+
+	switch len(creds) {
+	case 0:
+	case 1:
+		opts = append(opts, api.WithCredentials(creds[0]))
+	default:
+		return nil, fmt.Errorf(`-user, -token-file and -token-env are mutually exclusive`)
+	}
+
+	if *fPageSize > 0 {
+		opts = append(opts, api.WithPageSize(*fPageSize))
+	}
+
+	if *fTimeout > 0 {
+		opts = append(opts, api.WithTimeout(*fTimeout))
+	}
+
+	if *fCaCert != `` || *fCert != `` || *fCertKey != `` {
+		if cfg, err := api.TLSConfig(*fCaCert, *fCert, *fCertKey); err != nil {
+			return nil, err
+		} else {
+			opts = append(opts, api.WithTLSConfig(cfg))
+		}
+	}
+
+	if *fProxy != `` {
+		if u, err := url.Parse(*fProxy); err != nil {
+			return nil, fmt.Errorf(`invalid -proxy: %v`, err)
+		} else {
+			opts = append(opts, api.WithProxy(u))
+		}
+	}
+
+	if *fRetries > 0 {
+		policy := api.DefaultRetryPolicy()
+		policy.MaxAttempts = *fRetries + 1
+		opts = append(opts, api.WithRetry(policy))
+	}
+
+	if *fBreaker > 0 {
+		opts = append(opts, api.WithCircuitBreaker(api.NewCircuitBreaker(*fBreaker, breakerCooldown)))
+	}
+
+	return opts, nil
+}